@@ -0,0 +1,168 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+// maxAbsenceDays is the longest span, in days, of an absence or an imported holiday.
+const maxAbsenceDays = 366
+
+func getAbsences(w http.ResponseWriter, r *http.Request) {
+	renderAbsences(w, getRequestUser(r))
+}
+
+func addAbsence(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	absence := models.Absence{
+		ID:      uuid.New(),
+		User:    editor.Username,
+		Kind:    r.FormValue("kind"),
+		Note:    strings.TrimSpace(r.FormValue("note")),
+		Updated: time.Now(),
+	}
+	if user := r.FormValue("user"); user != "" && user != editor.Username {
+		if !editor.IsAdmin {
+			processError(w, http.StatusUnauthorized, "you are not authorized to add absences for other users")
+			return
+		}
+		if _, err := database.GetUser(user); err != nil {
+			processError(w, http.StatusBadRequest, "user does not exist")
+			return
+		}
+		absence.User = user
+	}
+	if !models.ValidAbsence(absence.Kind) {
+		processError(w, http.StatusBadRequest, "invalid absence kind")
+		return
+	}
+	location := userLocation(absence.User)
+	var err error
+	absence.Start, err = time.ParseInLocation("2006-01-02", r.FormValue("start"), location)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	absence.End = absence.Start
+	if end := r.FormValue("end"); end != "" {
+		absence.End, err = time.ParseInLocation("2006-01-02", end, location)
+		if err != nil {
+			processError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if absence.End.Before(absence.Start) {
+		processError(w, http.StatusBadRequest, "end must not be before start")
+		return
+	}
+	if !absence.End.Before(absence.Start.AddDate(0, 0, maxAbsenceDays)) {
+		processError(w, http.StatusBadRequest, "absences cannot span more than a year")
+		return
+	}
+	if err := database.SaveAbsence(&absence); err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	slog.Info("absence added", "user", absence.User, "kind", absence.Kind)
+	renderAbsences(w, editor)
+}
+
+func deleteAbsence(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	absence, err := database.GetAbsence(id)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if absence.User != editor.Username && !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to delete this absence")
+		return
+	}
+	if err := database.DeleteAbsence(id); err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	slog.Info("absence deleted", "user", absence.User, "kind", absence.Kind)
+	renderAbsences(w, editor)
+}
+
+func renderAbsences(w http.ResponseWriter, editor models.User) {
+	page := models.AbsencePage{
+		AsAdmin:     editor.IsAdmin,
+		DefaultDate: time.Now().Format("2006-01-02"),
+	}
+	absences, err := database.GetAllAbsences()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, absence := range absences {
+		if absence.User == editor.Username || editor.IsAdmin {
+			page.Absences = append(page.Absences, absence)
+		}
+	}
+	slices.SortFunc(page.Absences, func(a, b models.Absence) int {
+		return b.Start.Compare(a.Start)
+	})
+	if editor.IsAdmin {
+		users, err := database.GetAllUsers()
+		if err != nil {
+			processError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		for _, user := range users {
+			page.Users = append(page.Users, user.Username)
+		}
+	}
+	render(w, "absences", page)
+}
+
+// userDaysOff returns the days from start up to end, in the user's time zone, on
+// which user is absent or which are holidays in the user's holiday calendar.
+// Absences and holidays are whole days, so they are compared by date.
+func userDaysOff(user models.User, start, end time.Time) models.DaysOff {
+	location := user.Location()
+	from := startOfDay(start.In(location))
+	first := from.Format("2006-01-02")
+	last := end.In(location).Add(-time.Nanosecond).Format("2006-01-02")
+	off := models.DaysOff{}
+	if user.Holidays != "" {
+		calendar, err := database.GetHolidayCalendar(user.Holidays)
+		if err != nil {
+			slog.Error("get holiday calendar", "calendar", user.Holidays, "error", err)
+		}
+		for _, holiday := range calendar.Holidays {
+			if date := holiday.Date.Format("2006-01-02"); date >= first && date <= last {
+				off[date] = holiday.Name
+			}
+		}
+	}
+	absences, err := database.GetAbsencesForUser(user.Username)
+	if err != nil {
+		slog.Error("get absences", "user", user.Username, "error", err)
+	}
+	for _, absence := range absences {
+		year, month, date := absence.Start.Date()
+		day := time.Date(year, month, date, 0, 0, 0, 0, location)
+		if day.Before(from) {
+			day = from
+		}
+		until := min(absence.End.Format("2006-01-02"), last)
+		for ; day.Format("2006-01-02") <= until; day = day.AddDate(0, 0, 1) {
+			off[day.Format("2006-01-02")] = absence.Kind
+		}
+	}
+	return off
+}
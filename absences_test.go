@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+)
+
+const testICS = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20261225\r\nDTEND;VALUE=DATE:20261227\r\n" +
+	"SUMMARY:Christmas\\, Boxing\r\n  Day\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nDTSTART:20260701T000000Z\r\nSUMMARY:Canada Day\r\nEND:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestAbsences(t *testing.T) {
+	deleteAllAbsences()
+	deleteAllUsers()
+	createAdmin()
+	worker := models.User{Username: "worker", Password: "testing"}
+	should.BeNil(t, createTestUser(worker))
+	request := func(cookie *http.Cookie, method, url string, params ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, bodyParams(params...))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	t.Run("invalid", func(t *testing.T) {
+		w := request(testLogin(worker), http.MethodPost, "/absences/", "kind", "party", "start", "2026-10-19")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		w = request(testLogin(worker), http.MethodPost, "/absences/",
+			"kind", "vacation", "start", "2026-10-19", "end", "2026-10-18")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		w = request(testLogin(worker), http.MethodPost, "/absences/",
+			"kind", "vacation", "start", "2026-10-19", "user", "admin")
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+		w = request(testLogin(worker), http.MethodPost, "/absences/",
+			"kind", "vacation", "start", "2026-10-19", "end", "9999-12-31")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "more than a year")
+	})
+	t.Run("add", func(t *testing.T) {
+		w := request(testLogin(worker), http.MethodPost, "/absences/",
+			"kind", "vacation", "start", "2026-10-19", "end", "2026-10-21", "note", "cabin")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "cabin")
+		w = request(adminLogin(), http.MethodPost, "/absences/",
+			"kind", "sick", "start", "2026-10-23", "user", "worker")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		absences, err := database.GetAbsencesForUser("worker")
+		should.BeNil(t, err)
+		should.BeEqual(t, len(absences), 2)
+		user, err := database.GetUser("worker")
+		should.BeNil(t, err)
+		off := userDaysOff(user, time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local),
+			time.Date(2026, 11, 1, 0, 0, 0, 0, time.Local))
+		should.BeEqual(t, len(off), 4)
+		should.BeEqual(t, off["2026-10-20"], models.AbsenceVacation)
+		should.BeEqual(t, off["2026-10-23"], models.AbsenceSick)
+		off = userDaysOff(user, time.Date(2026, 10, 20, 12, 0, 0, 0, time.Local),
+			time.Date(2026, 10, 23, 0, 0, 0, 0, time.Local))
+		should.BeEqual(t, len(off), 2)
+		should.BeEqual(t, off["2026-10-19"], "")
+	})
+	t.Run("timeZone", func(t *testing.T) {
+		east := models.User{Username: "east", Password: "testing", TimeZone: "Pacific/Kiritimati"}
+		should.BeNil(t, createTestUser(east))
+		w := request(testLogin(east), http.MethodPost, "/absences/", "kind", "vacation", "start", "2026-10-19")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		absences, err := database.GetAbsencesForUser("east")
+		should.BeNil(t, err)
+		should.BeEqual(t, len(absences), 1)
+		location := east.Location()
+		should.BeTrue(t, absences[0].Start.Equal(time.Date(2026, 10, 19, 0, 0, 0, 0, location)))
+		day := time.Date(2026, 10, 19, 0, 0, 0, 0, location)
+		off := userDaysOff(east, day, day.AddDate(0, 0, 1))
+		should.BeEqual(t, len(off), 1)
+		should.BeEqual(t, off["2026-10-19"], models.AbsenceVacation)
+		should.BeNil(t, database.DeleteAbsence(absences[0].ID))
+	})
+	t.Run("calendar", func(t *testing.T) {
+		w := request(testLogin(worker), http.MethodGet, "/calendar/?month=2026-10")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "October 2026")
+		should.ContainSubstring(t, w.Body.String(), "vacation")
+		w = request(testLogin(worker), http.MethodGet, "/calendar/?month=junk")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+	t.Run("delete", func(t *testing.T) {
+		absences, err := database.GetAbsencesForUser("worker")
+		should.BeNil(t, err)
+		w := request(adminLogin(), http.MethodGet, "/absences/")
+		should.ContainSubstring(t, w.Body.String(), absences[0].ID.String())
+		should.BeNil(t, createTestUser(models.User{Username: "other", Password: "testing"}))
+		w = request(testLogin(models.User{Username: "other", Password: "testing"}),
+			http.MethodDelete, "/absences/"+absences[0].ID.String())
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+		w = request(testLogin(worker), http.MethodDelete, "/absences/"+absences[0].ID.String())
+		should.BeEqual(t, w.Code, http.StatusOK)
+		absences, err = database.GetAbsencesForUser("worker")
+		should.BeNil(t, err)
+		should.BeEqual(t, len(absences), 1)
+	})
+}
+
+func TestHolidays(t *testing.T) {
+	deleteAllHolidayCalendars()
+	deleteAllUsers()
+	createAdmin()
+	request := func(method, url string, params ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, bodyParams(params...))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(adminLogin())
+		router.ServeHTTP(w, req)
+		return w
+	}
+	t.Run("add", func(t *testing.T) {
+		w := request(http.MethodPost, "/holidays/", "calendar", "ontario", "date", "2026-08-03", "name", "Civic")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "Civic")
+		w = request(http.MethodPost, "/holidays/", "calendar", "", "date", "2026-08-03", "name", "Civic")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+	t.Run("import", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		should.BeNil(t, writer.WriteField("calendar", "ontario"))
+		part, err := writer.CreateFormFile("file", "holidays.ics")
+		should.BeNil(t, err)
+		_, err = part.Write([]byte(testICS))
+		should.BeNil(t, err)
+		should.BeNil(t, writer.Close())
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/holidays/import/", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.AddCookie(adminLogin())
+		router.ServeHTTP(w, req)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		calendar, err := database.GetHolidayCalendar("ontario")
+		should.BeNil(t, err)
+		should.BeEqual(t, len(calendar.Holidays), 4)
+		should.BeEqual(t, calendar.Holidays[0].Name, "Canada Day")
+		should.BeEqual(t, calendar.Holidays[3].Name, "Christmas, Boxing Day")
+	})
+	t.Run("assign", func(t *testing.T) {
+		w := request(http.MethodPost, "/users/schedule/admin", "monday", "8", "holidays", "missing")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		w = request(http.MethodPost, "/users/schedule/admin", "monday", "8", "holidays", "ontario")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		user, err := database.GetUser("admin")
+		should.BeNil(t, err)
+		off := userDaysOff(user, time.Date(2026, 12, 1, 0, 0, 0, 0, time.Local),
+			time.Date(2027, 1, 1, 0, 0, 0, 0, time.Local))
+		should.BeEqual(t, off["2026-12-25"], "Christmas, Boxing Day")
+		should.BeEqual(t, off["2026-07-01"], "")
+	})
+	t.Run("delete", func(t *testing.T) {
+		w := request(http.MethodDelete, "/holidays/ontario")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "used by admin")
+		w = request(http.MethodPost, "/users/schedule/admin", "monday", "8")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		w = request(http.MethodDelete, "/holidays/ontario")
+		should.BeEqual(t, w.Code, http.StatusOK)
+	})
+}
+
+func TestParseICS(t *testing.T) {
+	holidays, err := parseICS(strings.NewReader(testICS))
+	should.BeNil(t, err)
+	should.BeEqual(t, len(holidays), 3)
+	should.BeEqual(t, holidays[0].Date, time.Date(2026, 12, 25, 0, 0, 0, 0, time.Local))
+	should.BeEqual(t, holidays[1].Date, time.Date(2026, 12, 26, 0, 0, 0, 0, time.Local))
+	should.BeEqual(t, holidays[2].Name, "Canada Day")
+	_, err = parseICS(strings.NewReader("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+	should.NotBeNil(t, err)
+	_, err = parseICS(strings.NewReader("BEGIN:VEVENT\r\nDTSTART:2026\r\nEND:VEVENT\r\n"))
+	should.NotBeNil(t, err)
+	_, err = parseICS(strings.NewReader("BEGIN:VEVENT\r\nDTSTART:20260101\r\nDTEND:99991231\r\nEND:VEVENT\r\n"))
+	should.NotBeNil(t, err)
+}
+
+func TestCalendarMonth(t *testing.T) {
+	start := time.Date(2026, 10, 6, 9, 0, 0, 0, time.Local)
+	records := []models.Record{
+		{Start: start, End: start.Add(2 * time.Hour)},
+		{Start: start.Add(13 * time.Hour), End: start.Add(17 * time.Hour)},
+	}
+	off := models.DaysOff{"2026-10-07": "vacation"}
+	calendar := calendarMonth(start, records, off, start)
+	should.BeEqual(t, calendar.Previous, "2026-09")
+	should.BeEqual(t, calendar.Next, "2026-11")
+	should.BeEqual(t, len(calendar.Weeks), 5)
+	// October 2026 starts on a Thursday
+	should.BeFalse(t, calendar.Weeks[0][2].InMonth)
+	should.BeEqual(t, calendar.Weeks[0][3].Date.Day(), 1)
+	should.BeEqual(t, calendar.Weeks[1][1].Worked, 4*time.Hour)
+	should.BeEqual(t, calendar.Weeks[1][2].Worked, 2*time.Hour)
+	should.BeEqual(t, calendar.Weeks[1][2].Off, "vacation")
+}
+
+func deleteAllAbsences() {
+	absences, _ := database.GetAllAbsences()
+	for _, absence := range absences {
+		_ = database.DeleteAbsence(absence.ID)
+	}
+}
+
+func deleteAllHolidayCalendars() {
+	calendars, _ := database.GetAllHolidayCalendars()
+	for _, calendar := range calendars {
+		_ = database.DeleteHolidayCalendar(calendar.Name)
+	}
+}
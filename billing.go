@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+)
+
+// billing provides the project and client details needed to value records.
+type billing struct {
+	projects map[string]models.Project
+	clients  map[string]models.Client
+}
+
+// loadBilling reads all projects and clients from the db.
+func loadBilling() (billing, error) {
+	b := billing{
+		projects: map[string]models.Project{},
+		clients:  map[string]models.Client{},
+	}
+	projects, err := database.GetAllProjects()
+	if err != nil {
+		return b, err
+	}
+	for _, project := range projects {
+		b.projects[project.Name] = project
+	}
+	clients, err := database.GetAllClients()
+	if err != nil {
+		return b, err
+	}
+	for _, client := range clients {
+		b.clients[client.Name] = client
+	}
+	return b, nil
+}
+
+// rate returns the hourly rate, in cents, of a record.  Project rates take precedence
+// over the default rate of the project's client.
+func (b billing) rate(record models.Record) int64 {
+	project := b.projects[record.Project]
+	if rate := project.RateFor(record.User, record.Start); rate != 0 {
+		return rate
+	}
+	return b.clients[project.Client].Rate
+}
+
+// client returns the client of the named project.
+func (b billing) client(project string) models.Client {
+	return b.clients[b.projects[project].Client]
+}
+
+// rounding returns the rounding policy of the named project.  A project without a
+// policy uses that of its client.
+func (b billing) rounding(project string) models.Rounding {
+	if rounding := b.projects[project].Rounding; rounding.IsSet() {
+		return rounding
+	}
+	return b.client(project).Rounding
+}
+
+// parseRounding returns the rounding policy submitted in a project or client form.
+func parseRounding(r *http.Request) (models.Rounding, error) {
+	rounding := models.Rounding{
+		Mode:     r.FormValue("roundingMode"),
+		PerTotal: r.FormValue("roundingScope") == "total",
+	}
+	if rounding.Mode == models.RoundNone {
+		return rounding, nil
+	}
+	minutes, err := strconv.Atoi(r.FormValue("roundingMinutes"))
+	if err != nil {
+		return rounding, errors.New("invalid rounding")
+	}
+	rounding.Minutes = minutes
+	if !rounding.Valid() {
+		return rounding, errors.New("invalid rounding")
+	}
+	return rounding, nil
+}
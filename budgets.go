@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+)
+
+// webhookClient posts budget alerts to the url set in the BUDGET_WEBHOOK environment variable.
+var webhookClient = &http.Client{Timeout: 5 * time.Second}
+
+// budgetAlert is the payload posted to the budget webhook.
+type budgetAlert struct {
+	Project   string  `json:"project"`
+	Budget    string  `json:"budget"`
+	Threshold int     `json:"threshold"`
+	Used      float64 `json:"used_hours"`
+	Limit     float64 `json:"budget_hours"`
+}
+
+// budgetRecords returns the records of the projects with a budget, by project.  The
+// records of all projects are read at once; nil is returned without reading them if
+// no project has a budget.
+func budgetRecords(projects []models.Project) map[string][]models.Record {
+	if !slices.ContainsFunc(projects, func(project models.Project) bool {
+		return project.Budget > 0 || project.PeriodBudget > 0
+	}) {
+		return nil
+	}
+	records, err := database.GetAllRecords()
+	if err != nil {
+		slog.Error("get records", "error", err)
+		return nil
+	}
+	byProject := map[string][]models.Record{}
+	for _, record := range records {
+		byProject[record.Project] = append(byProject[record.Project], record)
+	}
+	return byProject
+}
+
+// projectBudgets returns the consumption of the total and current period budgets of
+// project by its records, rounded like the project's report.  Records still being
+// tracked are counted up to now.
+func projectBudgets(project models.Project, records []models.Record, rounding models.Rounding) []models.BudgetStatus {
+	if project.Budget <= 0 && project.PeriodBudget <= 0 {
+		return nil
+	}
+	now := time.Now()
+	total := models.BudgetStatus{Project: project.Name, Color: project.Color, Budget: project.Budget}
+	period := models.BudgetStatus{
+		Project: project.Name,
+		Color:   project.Color,
+		Period:  project.BudgetPeriod,
+		Budget:  project.PeriodBudget,
+	}
+	start := models.PeriodStart(project.BudgetPeriod, now)
+	end := models.PeriodEnd(project.BudgetPeriod, now)
+	for _, record := range records {
+		if record.End.IsZero() {
+			record.End = now
+		}
+		total.Used += rounding.Record(record.Duration())
+		if record.Clip(start, end) {
+			period.Used += rounding.Record(record.Duration())
+		}
+	}
+	total.Used = rounding.Total(total.Used)
+	period.Used = rounding.Total(period.Used)
+	budgets := []models.BudgetStatus{}
+	if project.Budget > 0 {
+		budgets = append(budgets, total)
+	}
+	if project.PeriodBudget > 0 {
+		budgets = append(budgets, period)
+	}
+	return budgets
+}
+
+// checkBudget raises an alert for each budget threshold of the named project that
+// has been crossed and not yet alerted on.  Each alert is passed to notify.
+func checkBudget(name string, notify func(models.BudgetStatus, int)) {
+	billing, err := loadBilling()
+	if err != nil {
+		slog.Error("load billing", "error", err)
+		return
+	}
+	project, ok := billing.projects[name]
+	if !ok {
+		slog.Error("get project", "project", name, "error", "no such project")
+		return
+	}
+	raised := false
+	records := budgetRecords([]models.Project{project})
+	for _, status := range projectBudgets(project, records[project.Name], billing.rounding(name)) {
+		threshold := status.Threshold()
+		if threshold == 0 {
+			continue
+		}
+		period := ""
+		if status.Period != "" {
+			period = models.PeriodStart(status.Period, time.Now()).Format("2006-01-02")
+		}
+		if slices.ContainsFunc(project.Alerts, func(a models.BudgetAlert) bool {
+			return a.Period == period && a.Threshold >= threshold
+		}) {
+			continue
+		}
+		project.Alerts = append(project.Alerts, models.BudgetAlert{
+			Period:    period,
+			Threshold: threshold,
+			Raised:    time.Now(),
+		})
+		raised = true
+		slog.Warn("budget threshold reached", "project", name, "budget", status.Label(),
+			"threshold", threshold)
+		notify(status, threshold)
+	}
+	if !raised {
+		return
+	}
+	if err := database.SaveProject(&project); err != nil {
+		slog.Error("save project", "project", name, "error", err)
+	}
+}
+
+// alertBudget notifies the budget webhook in the background so a slow webhook does
+// not hold up saving records.  The post is abandoned after the timeout of
+// webhookClient.
+func alertBudget(status models.BudgetStatus, threshold int) {
+	go notifyBudget(status, threshold)
+}
+
+// notifyBudget posts a budget alert to the budget webhook, if one is configured.
+func notifyBudget(status models.BudgetStatus, threshold int) {
+	url := os.Getenv("BUDGET_WEBHOOK")
+	if url == "" {
+		return
+	}
+	payload, err := json.Marshal(budgetAlert{
+		Project:   status.Project,
+		Budget:    status.Label(),
+		Threshold: threshold,
+		Used:      status.Used.Hours(),
+		Limit:     status.Budget.Hours(),
+	})
+	if err != nil {
+		slog.Error("marshal budget alert", "error", err)
+		return
+	}
+	response, err := webhookClient.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		slog.Error("budget webhook", "error", err)
+		return
+	}
+	_ = response.Body.Close()
+	if response.StatusCode >= http.StatusBadRequest {
+		slog.Error("budget webhook", "status", response.Status)
+	}
+}
+
+// setBudget updates the budget of project from the edit form.  Raised alerts are
+// cleared when the budget changes so thresholds are checked against the new budget.
+func setBudget(r *http.Request, project *models.Project) error {
+	budget, err := parseHours(r.FormValue("budget"))
+	if err != nil {
+		return errors.New("invalid budget")
+	}
+	periodBudget, err := parseHours(r.FormValue("periodBudget"))
+	if err != nil {
+		return errors.New("invalid budget")
+	}
+	period := r.FormValue("period")
+	if periodBudget > 0 && !models.ValidPeriod(period) {
+		return errors.New("invalid budget period")
+	}
+	if budget != project.Budget || periodBudget != project.PeriodBudget ||
+		period != project.BudgetPeriod {
+		project.Alerts = nil
+	}
+	project.Budget = budget
+	project.PeriodBudget = periodBudget
+	project.BudgetPeriod = period
+	return nil
+}
+
+// parseHours parses a number of hours.  A blank value is zero.
+func parseHours(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	hours, err := strconv.ParseFloat(value, 64)
+	if err != nil || hours < 0 {
+		return 0, errors.New("invalid hours")
+	}
+	return time.Duration(hours * float64(time.Hour)), nil
+}
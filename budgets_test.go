@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+func TestBudgets(t *testing.T) {
+	deleteAllRecords()
+	deleteAllProjects()
+	createTestProjects()
+	deleteAllUsers()
+	createAdmin()
+	alerts := []budgetAlert{}
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		alert := budgetAlert{}
+		_ = json.NewDecoder(r.Body).Decode(&alert)
+		mu.Lock()
+		alerts = append(alerts, alert)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	t.Setenv("BUDGET_WEBHOOK", server.URL)
+	request := func(method, url string, params ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, bodyParams(params...))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(adminLogin())
+		router.ServeHTTP(w, req)
+		return w
+	}
+	// notify synchronously so the alerts have been posted when checkBudget returns
+	check := func() {
+		checkBudget("test", notifyBudget)
+	}
+	addRecord := func(d time.Duration) {
+		end := time.Now().Add(-time.Minute)
+		should.BeNil(t, database.SaveRecord(&models.Record{
+			ID:      uuid.New(),
+			Project: "test",
+			User:    "admin",
+			Start:   end.Add(-d),
+			End:     end,
+		}))
+	}
+	t.Run("invalid", func(t *testing.T) {
+		w := request(http.MethodPost, "/projects/edit/test", "budget", "-1")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "invalid budget")
+		w = request(http.MethodPost, "/projects/edit/test", "periodBudget", "5", "period", "year")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "invalid budget period")
+	})
+	t.Run("set", func(t *testing.T) {
+		w := request(http.MethodPost, "/projects/edit/test",
+			"budget", "10", "periodBudget", "2.5", "period", "week")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), `value="2.5"`)
+		project, err := database.GetProject("test")
+		should.BeNil(t, err)
+		should.BeEqual(t, project.Budget, 10*time.Hour)
+		should.BeEqual(t, project.PeriodBudget, 150*time.Minute)
+		should.BeEqual(t, project.BudgetPeriod, models.PeriodWeek)
+	})
+	t.Run("belowThreshold", func(t *testing.T) {
+		addRecord(time.Hour)
+		check()
+		should.BeEmpty(t, alerts)
+		w := request(http.MethodGet, "/status/")
+		should.ContainSubstring(t, w.Body.String(), "Budgets")
+		should.ContainSubstring(t, w.Body.String(), "This week")
+	})
+	t.Run("periodWarning", func(t *testing.T) {
+		addRecord(time.Hour)
+		check()
+		should.BeEqual(t, len(alerts), 1)
+		should.BeEqual(t, alerts[0].Budget, "This week")
+		should.BeEqual(t, alerts[0].Threshold, 80)
+		check()
+		should.BeEqual(t, len(alerts), 1)
+		w := request(http.MethodGet, "/status/")
+		should.ContainSubstring(t, w.Body.String(), "80% of budget used")
+	})
+	t.Run("exceeded", func(t *testing.T) {
+		addRecord(8 * time.Hour)
+		check()
+		should.BeEqual(t, len(alerts), 3)
+		should.BeEqual(t, alerts[1].Budget, "Total")
+		should.BeEqual(t, alerts[1].Threshold, 100)
+		should.BeEqual(t, alerts[2].Threshold, 100)
+		project, err := database.GetProject("test")
+		should.BeNil(t, err)
+		should.BeEqual(t, len(project.Alerts), 3)
+		w := request(http.MethodPost, "/reports/", "start", time.Now().AddDate(0, 0, -1).Format("2006-01-02"),
+			"end", time.Now().Format("2006-01-02"), "project", "test")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "budget exceeded")
+	})
+	t.Run("changeResetsAlerts", func(t *testing.T) {
+		w := request(http.MethodPost, "/projects/edit/test", "budget", "20")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		project, err := database.GetProject("test")
+		should.BeNil(t, err)
+		should.BeEmpty(t, project.Alerts)
+	})
+}
+
+func TestProjectBudgets(t *testing.T) {
+	project := models.Project{
+		Name: "test", Budget: 10 * time.Hour, PeriodBudget: 5 * time.Hour, BudgetPeriod: models.PeriodWeek,
+	}
+	start := models.PeriodStart(models.PeriodWeek, time.Now())
+	records := []models.Record{{Project: "test", Start: start.Add(-2 * time.Hour), End: start.Add(time.Hour)}}
+	budgets := projectBudgets(project, records, models.Rounding{})
+	should.BeEqual(t, len(budgets), 2)
+	should.BeEqual(t, budgets[0].Used, 3*time.Hour)
+	// only the time within the week counts toward the weekly budget
+	should.BeEqual(t, budgets[1].Used, time.Hour)
+	// usage is rounded like the project's report
+	hourly := models.Rounding{Mode: models.RoundUp, Minutes: 60, PerTotal: true}
+	budgets = projectBudgets(project, records, hourly)
+	should.BeEqual(t, budgets[0].Used, 3*time.Hour)
+	records[0].End = records[0].End.Add(10 * time.Minute)
+	budgets = projectBudgets(project, records, hourly)
+	should.BeEqual(t, budgets[0].Used, 4*time.Hour)
+	should.BeEqual(t, budgets[1].Used, 2*time.Hour)
+}
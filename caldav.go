@@ -0,0 +1,402 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+const (
+	davPrefix = "/dav/"
+	// maxDavBody is the maximum size of a CalDAV request body.
+	maxDavBody = 1 << 20
+)
+
+// davMultistatus is a WebDAV multi-status response.  Element names carry their
+// namespace prefixes, which are declared on the root element.
+type davMultistatus struct {
+	XMLName   xml.Name      `xml:"D:multistatus"`
+	DAV       string        `xml:"xmlns:D,attr"`
+	CalDAV    string        `xml:"xmlns:C,attr"`
+	Server    string        `xml:"xmlns:CS,attr"`
+	Responses []davResponse `xml:"D:response"`
+}
+
+type davResponse struct {
+	Href     string      `xml:"D:href"`
+	Propstat davPropstat `xml:"D:propstat"`
+}
+
+type davPropstat struct {
+	Prop   davProp `xml:"D:prop"`
+	Status string  `xml:"D:status"`
+}
+
+type davProp struct {
+	ResourceType         *davResourceType `xml:"D:resourcetype,omitempty"`
+	DisplayName          string           `xml:"D:displayname,omitempty"`
+	CurrentUserPrincipal *davHref         `xml:"D:current-user-principal,omitempty"`
+	CalendarHomeSet      *davHref         `xml:"C:calendar-home-set,omitempty"`
+	ComponentSet         *davComponentSet `xml:"C:supported-calendar-component-set,omitempty"`
+	CTag                 string           `xml:"CS:getctag,omitempty"`
+	ETag                 string           `xml:"D:getetag,omitempty"`
+	ContentType          string           `xml:"D:getcontenttype,omitempty"`
+	CalendarData         string           `xml:"C:calendar-data,omitempty"`
+}
+
+type davResourceType struct {
+	Collection *struct{} `xml:"D:collection,omitempty"`
+	Calendar   *struct{} `xml:"C:calendar,omitempty"`
+}
+
+type davHref struct {
+	Href string `xml:"D:href"`
+}
+
+type davComponentSet struct {
+	Component davComponent `xml:"C:comp"`
+}
+
+type davComponent struct {
+	Name string `xml:"name,attr"`
+}
+
+// davQuery is a parsed CalDAV REPORT request: the hrefs of a calendar-multiget, or
+// the time range of a calendar-query.
+type davQuery struct {
+	Multiget bool
+	Hrefs    []string
+	Start    time.Time
+	End      time.Time
+}
+
+// davWellKnown redirects calendar clients discovering the server to the CalDAV root.
+// The redirect is temporary so that clients repeat the PROPFIND rather than a GET.
+func davWellKnown(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, davPrefix, http.StatusTemporaryRedirect)
+}
+
+// davOptions advertises the CalDAV capabilities of the server.
+func davOptions(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Dav", "1, calendar-access")
+	w.Header().Set("Allow", "OPTIONS, GET, PROPFIND, REPORT")
+	w.WriteHeader(http.StatusOK)
+}
+
+// davPrincipal describes the principal of the authenticated user, which clients use
+// to discover the calendar.
+func davPrincipal(w http.ResponseWriter, r *http.Request) {
+	home := davHome(getRequestUser(r).Username)
+	writeMultistatus(w, []davResponse{{
+		Href: davPrefix,
+		Propstat: davPropstat{Prop: davProp{
+			ResourceType:         &davResourceType{Collection: &struct{}{}},
+			CurrentUserPrincipal: &davHref{Href: davPrefix},
+			CalendarHomeSet:      &davHref{Href: home},
+		}},
+	}})
+}
+
+// davCalendar describes the calendar collection of a user and, unless the Depth
+// header is 0, the events of the last feedDays days.
+func davCalendar(w http.ResponseWriter, r *http.Request) {
+	user, ok := davUser(w, r)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	records, err := davRecords(user, now.AddDate(0, 0, -feedDays), now)
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responses := []davResponse{{
+		Href: davHome(user),
+		Propstat: davPropstat{Prop: davProp{
+			ResourceType: &davResourceType{Collection: &struct{}{}, Calendar: &struct{}{}},
+			DisplayName:  "Timetrace",
+			ComponentSet: &davComponentSet{Component: davComponent{Name: "VEVENT"}},
+			CTag:         davCTag(records),
+		}},
+	}}
+	if r.Header.Get("Depth") != "0" {
+		for _, record := range records {
+			responses = append(responses, davEventResponse(user, record, false))
+		}
+	}
+	writeMultistatus(w, responses)
+}
+
+// davReport answers calendar-query and calendar-multiget reports with the events and
+// their calendar data.
+func davReport(w http.ResponseWriter, r *http.Request) {
+	user, ok := davUser(w, r)
+	if !ok {
+		return
+	}
+	query, err := parseDavQuery(http.MaxBytesReader(w, r.Body, maxDavBody))
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	records := []models.Record{}
+	responses := []davResponse{}
+	if query.Multiget {
+		for _, href := range query.Hrefs {
+			record, err := davRecord(user, href)
+			if err != nil {
+				responses = append(responses, davResponse{
+					Href:     href,
+					Propstat: davPropstat{Status: "HTTP/1.1 404 Not Found"},
+				})
+				continue
+			}
+			records = append(records, record)
+		}
+	} else {
+		records, err = davRecords(user, query.Start, query.End)
+		if err != nil {
+			processError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	for _, record := range records {
+		responses = append(responses, davEventResponse(user, record, true))
+	}
+	writeMultistatus(w, responses)
+}
+
+// davEvent returns the calendar data of an event.
+func davEvent(w http.ResponseWriter, r *http.Request) {
+	user, ok := davUser(w, r)
+	if !ok {
+		return
+	}
+	record, err := davRecord(user, r.PathValue("event"))
+	if err != nil {
+		processError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Etag", davETag(record))
+	if err := writeICS(w, []exportRow{recordRow(record)}, time.Now()); err != nil {
+		slog.Error("write event", "error", err)
+	}
+}
+
+// davUser returns the user whose calendar is requested, which must be the
+// authenticated user.
+func davUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	user := r.PathValue("user")
+	if user != getRequestUser(r).Username {
+		processError(w, http.StatusForbidden, "you are not authorized to view this calendar")
+		return "", false
+	}
+	return user, true
+}
+
+// davRecords returns the records of the user overlapping start and end, sorted by
+// start time.  Events keep their full times rather than being clipped to the range.
+func davRecords(user string, start, end time.Time) ([]models.Record, error) {
+	projects, err := database.GetAllProjects()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	records := []models.Record{}
+	for _, project := range projects {
+		data, err := database.GetReportRecords(models.DatabaseReportRequest{
+			User:    user,
+			Project: project.Name,
+			Start:   start,
+			End:     end,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range data {
+			// reports cover whole days
+			if !record.Clip(start, end) {
+				continue
+			}
+			full, err := database.GetRecord(record.ID)
+			if err != nil {
+				return nil, err
+			}
+			if full.End.IsZero() {
+				full.End = now
+			}
+			records = append(records, full)
+		}
+	}
+	slices.SortFunc(records, func(a, b models.Record) int { return a.Start.Compare(b.Start) })
+	return records, nil
+}
+
+// davRecord returns the record of an event href or file name belonging to the user.
+func davRecord(user, href string) (models.Record, error) {
+	id, err := uuid.Parse(strings.TrimSuffix(path.Base(href), ".ics"))
+	if err != nil {
+		return models.Record{}, errors.New("no such event")
+	}
+	record, err := database.GetRecord(id)
+	if err != nil || record.User != user {
+		return models.Record{}, errors.New("no such event")
+	}
+	if record.End.IsZero() {
+		record.End = time.Now()
+	}
+	return record, nil
+}
+
+// davEventResponse describes an event, optionally with its calendar data.
+func davEventResponse(user string, record models.Record, data bool) davResponse {
+	response := davResponse{
+		Href: davHome(user) + record.ID.String() + ".ics",
+		Propstat: davPropstat{Prop: davProp{
+			ETag:        davETag(record),
+			ContentType: "text/calendar; component=vevent",
+		}},
+	}
+	if data {
+		var buf bytes.Buffer
+		_ = writeICS(&buf, []exportRow{recordRow(record)}, time.Now())
+		response.Propstat.Prop.CalendarData = buf.String()
+	}
+	return response
+}
+
+// davHome returns the path of the calendar collection of a user.
+func davHome(user string) string {
+	return davPrefix + user + "/"
+}
+
+// davETag returns an entity tag which changes whenever a record is edited.
+func davETag(record models.Record) string {
+	hash := fnv.New64a()
+	fmt.Fprint(hash, record.Project, record.Task, record.Start.UnixNano(), record.End.UnixNano(), record.Note)
+	return fmt.Sprintf(`"%x"`, hash.Sum64())
+}
+
+// davCTag returns a tag which changes whenever any of the records changes.
+func davCTag(records []models.Record) string {
+	hash := fnv.New64a()
+	for _, record := range records {
+		fmt.Fprint(hash, record.ID, davETag(record))
+	}
+	return fmt.Sprintf("%x", hash.Sum64())
+}
+
+// recordRow returns a record as a row for export.
+func recordRow(record models.Record) exportRow {
+	return exportRow{
+		ID:       record.ID,
+		Project:  record.Project,
+		Task:     record.Task,
+		User:     record.User,
+		Start:    record.Start,
+		End:      record.End,
+		Duration: record.Duration(),
+		Billable: record.Billable,
+		Note:     record.Note,
+	}
+}
+
+// parseDavQuery parses a REPORT request body.  A calendar-query without a time range
+// covers the last feedDays days.
+func parseDavQuery(r io.Reader) (davQuery, error) {
+	now := time.Now()
+	query := davQuery{Start: now.AddDate(0, 0, -feedDays), End: now}
+	decoder := xml.NewDecoder(r)
+	root := true
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return query, nil
+		}
+		if err != nil {
+			return query, err
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch {
+		case root:
+			root = false
+			switch element.Name.Local {
+			case "calendar-multiget":
+				query.Multiget = true
+			case "calendar-query":
+			default:
+				return query, errors.New("unsupported report " + element.Name.Local)
+			}
+		case element.Name.Local == "href":
+			var href string
+			if err := decoder.DecodeElement(&href, &element); err != nil {
+				return query, err
+			}
+			query.Hrefs = append(query.Hrefs, strings.TrimSpace(href))
+		case element.Name.Local == "time-range":
+			if err := parseTimeRange(element, &query); err != nil {
+				return query, err
+			}
+		}
+	}
+}
+
+// parseTimeRange sets the start and end of a query from a time-range element.  A
+// missing start or end leaves the range open.
+func parseTimeRange(element xml.StartElement, query *davQuery) error {
+	query.Start, query.End = time.Time{}, time.Now()
+	for _, attr := range element.Attr {
+		t, err := time.Parse(icsTimeFormat, attr.Value)
+		if err != nil {
+			return err
+		}
+		switch attr.Name.Local {
+		case "start":
+			query.Start = t
+		case "end":
+			query.End = t
+		}
+	}
+	return nil
+}
+
+// writeMultistatus writes a multi-status response.  Responses without a status are
+// found.
+func writeMultistatus(w http.ResponseWriter, responses []davResponse) {
+	for i := range responses {
+		if responses[i].Propstat.Status == "" {
+			responses[i].Propstat.Status = "HTTP/1.1 200 OK"
+		}
+	}
+	body, err := xml.Marshal(davMultistatus{
+		DAV:       "DAV:",
+		CalDAV:    "urn:ietf:params:xml:ns:caldav",
+		Server:    "http://calendarserver.org/ns/",
+		Responses: responses,
+	})
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(body)
+}
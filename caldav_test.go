@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+// davClient is a minimal CalDAV client.
+type davClient struct {
+	server   *httptest.Server
+	user     string
+	password string
+}
+
+// multistatus is a multi-status response as seen by a client.
+type multistatus struct {
+	Responses []struct {
+		Href string `xml:"href"`
+		Prop struct {
+			ResourceType struct {
+				Calendar *struct{} `xml:"calendar"`
+			} `xml:"resourcetype"`
+			CalendarHomeSet string `xml:"calendar-home-set>href"`
+			CTag            string `xml:"getctag"`
+			ETag            string `xml:"getetag"`
+			CalendarData    string `xml:"calendar-data"`
+		} `xml:"propstat>prop"`
+		Status string `xml:"propstat>status"`
+	} `xml:"response"`
+}
+
+func (c davClient) do(method, path, depth, body string) (*http.Response, multistatus) {
+	req, _ := http.NewRequest(method, c.server.URL+path, strings.NewReader(body))
+	req.SetBasicAuth(c.user, c.password)
+	req.Header.Set("Content-Type", "application/xml")
+	if depth != "" {
+		req.Header.Set("Depth", depth)
+	}
+	resp, err := c.server.Client().Do(req)
+	if err != nil {
+		return &http.Response{}, multistatus{}
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	status := multistatus{}
+	_ = xml.Unmarshal(data, &status)
+	resp.Body = io.NopCloser(strings.NewReader(string(data)))
+	return resp, status
+}
+
+func TestCalDAV(t *testing.T) {
+	deleteAllUsers()
+	deleteAllRecords()
+	deleteAllProjects()
+	createTestProjects()
+	should.BeNil(t, createTestUser(models.User{Username: "test", Password: "testing"}))
+	should.BeNil(t, createTestUser(models.User{Username: "other", Password: "testing"}))
+	recent := time.Now().Add(-3 * time.Hour)
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	records := []models.Record{
+		{ID: ids[0], Project: "test", User: "test", Start: recent, End: recent.Add(time.Hour), Note: "recent"},
+		{ID: ids[1], Project: "golf", User: "test", Start: recent.AddDate(0, 0, -10),
+			End: recent.AddDate(0, 0, -10).Add(time.Hour)},
+		{ID: ids[2], Project: "test", User: "other", Start: recent, End: recent.Add(time.Hour)},
+	}
+	for _, record := range records {
+		should.BeNil(t, database.SaveRecord(&record))
+	}
+	server := httptest.NewServer(router)
+	defer server.Close()
+	client := davClient{server: server, user: "test", password: "testing"}
+
+	t.Run("unauthorized", func(t *testing.T) {
+		resp, _ := davClient{server: server, user: "test", password: "wrong"}.do("PROPFIND", "/dav/", "0", "")
+		should.BeEqual(t, resp.StatusCode, http.StatusUnauthorized)
+		should.ContainSubstring(t, resp.Header.Get("WWW-Authenticate"), "Basic")
+	})
+	t.Run("options", func(t *testing.T) {
+		resp, _ := client.do(http.MethodOptions, "/dav/test/", "", "")
+		should.BeEqual(t, resp.StatusCode, http.StatusOK)
+		should.ContainSubstring(t, resp.Header.Get("DAV"), "calendar-access")
+	})
+	t.Run("discovery", func(t *testing.T) {
+		resp, status := client.do("PROPFIND", "/.well-known/caldav", "0", "")
+		should.BeEqual(t, resp.StatusCode, http.StatusMultiStatus)
+		should.BeEqual(t, status.Responses[0].Prop.CalendarHomeSet, "/dav/test/")
+	})
+	t.Run("collection", func(t *testing.T) {
+		resp, status := client.do("PROPFIND", "/dav/test/", "0", "")
+		should.BeEqual(t, resp.StatusCode, http.StatusMultiStatus)
+		should.BeEqual(t, len(status.Responses), 1)
+		should.NotBeNil(t, status.Responses[0].Prop.ResourceType.Calendar)
+		should.BeFalse(t, status.Responses[0].Prop.CTag == "")
+		_, status = client.do("PROPFIND", "/dav/test/", "1", "")
+		should.BeEqual(t, len(status.Responses), 3)
+		should.BeEqual(t, status.Responses[1].Href, "/dav/test/"+ids[1].String()+".ics")
+		should.BeFalse(t, status.Responses[1].Prop.ETag == "")
+	})
+	t.Run("otherUser", func(t *testing.T) {
+		resp, _ := client.do("PROPFIND", "/dav/other/", "1", "")
+		should.BeEqual(t, resp.StatusCode, http.StatusForbidden)
+		resp, _ = client.do(http.MethodGet, "/dav/test/"+ids[2].String()+".ics", "", "")
+		should.BeEqual(t, resp.StatusCode, http.StatusNotFound)
+	})
+	t.Run("query", func(t *testing.T) {
+		start := recent.AddDate(0, 0, -1).UTC().Format(icsTimeFormat)
+		body := `<?xml version="1.0"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop><D:getetag/><C:calendar-data/></D:prop>
+  <C:filter><C:comp-filter name="VCALENDAR"><C:comp-filter name="VEVENT">
+    <C:time-range start="` + start + `"/>
+  </C:comp-filter></C:comp-filter></C:filter>
+</C:calendar-query>`
+		resp, status := client.do("REPORT", "/dav/test/", "1", body)
+		should.BeEqual(t, resp.StatusCode, http.StatusMultiStatus)
+		should.BeEqual(t, len(status.Responses), 1)
+		should.ContainSubstring(t, status.Responses[0].Prop.CalendarData, "UID:"+ids[0].String()+"@timetrace")
+		should.ContainSubstring(t, status.Responses[0].Prop.CalendarData, "DESCRIPTION:recent")
+	})
+	t.Run("multiget", func(t *testing.T) {
+		body := `<C:calendar-multiget xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop><D:getetag/><C:calendar-data/></D:prop>
+  <D:href>/dav/test/` + ids[1].String() + `.ics</D:href>
+  <D:href>/dav/test/` + ids[2].String() + `.ics</D:href>
+</C:calendar-multiget>`
+		_, status := client.do("REPORT", "/dav/test/", "1", body)
+		should.BeEqual(t, len(status.Responses), 2)
+		should.BeEqual(t, status.Responses[0].Href, "/dav/test/"+ids[2].String()+".ics")
+		should.BeEqual(t, status.Responses[0].Status, "HTTP/1.1 404 Not Found")
+		should.BeEqual(t, status.Responses[1].Status, "HTTP/1.1 200 OK")
+		should.ContainSubstring(t, status.Responses[1].Prop.CalendarData, "SUMMARY:golf")
+	})
+	t.Run("unsupportedReport", func(t *testing.T) {
+		resp, _ := client.do("REPORT", "/dav/test/", "1", `<D:sync-collection xmlns:D="DAV:"/>`)
+		should.BeEqual(t, resp.StatusCode, http.StatusBadRequest)
+	})
+	t.Run("event", func(t *testing.T) {
+		resp, _ := client.do(http.MethodGet, "/dav/test/"+ids[0].String()+".ics", "", "")
+		should.BeEqual(t, resp.StatusCode, http.StatusOK)
+		should.BeEqual(t, resp.Header.Get("Content-Type"), "text/calendar; charset=utf-8")
+		should.BeFalse(t, resp.Header.Get("ETag") == "")
+		body, _ := io.ReadAll(resp.Body)
+		should.ContainSubstring(t, string(body), "SUMMARY:test")
+	})
+}
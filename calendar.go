@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+)
+
+func getCalendar(w http.ResponseWriter, r *http.Request) {
+	user, err := database.GetUser(getRequestUser(r).Username)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	location := user.Location()
+	month := time.Now().In(location)
+	if value := r.FormValue("month"); value != "" {
+		month, err = time.ParseInLocation("2006-01", value, location)
+		if err != nil {
+			processError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	records, err := database.GetAllRecordsForUser(user.Username)
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// the weeks shown start up to six days before the month and end up to six days after
+	first := models.PeriodStart(models.PeriodMonth, month)
+	off := userDaysOff(user, first.AddDate(0, 0, -7), first.AddDate(0, 1, 7))
+	render(w, "calendar", calendarMonth(month, records, off, time.Now()))
+}
+
+// calendarMonth returns the weeks of the month containing month with the time worked
+// and the absences on each day.  Records spanning midnight count on each day they
+// cover and records still being tracked are counted up to now.
+func calendarMonth(
+	month time.Time,
+	records []models.Record,
+	off models.DaysOff,
+	now time.Time,
+) models.CalendarMonth {
+	first := models.PeriodStart(models.PeriodMonth, month)
+	last := models.PeriodEnd(models.PeriodMonth, month)
+	calendar := models.CalendarMonth{
+		Month:    first,
+		Previous: first.AddDate(0, -1, 0).Format("2006-01"),
+		Next:     last.Format("2006-01"),
+	}
+	worked := map[string]time.Duration{}
+	for _, record := range records {
+		if record.End.IsZero() {
+			record.End = now
+		}
+		for _, part := range splitRecord(record, models.GroupDay, first.Location()) {
+			worked[part.Start.In(first.Location()).Format("2006-01-02")] += part.Duration()
+		}
+	}
+	day := models.PeriodStart(models.PeriodWeek, first)
+	for day.Before(last) {
+		week := []models.CalendarDay{}
+		for range 7 {
+			week = append(week, models.CalendarDay{
+				Date:    day,
+				InMonth: day.Month() == first.Month(),
+				Worked:  worked[day.Format("2006-01-02")],
+				Off:     off.Reason(day),
+			})
+			day = day.AddDate(0, 0, 1)
+		}
+		calendar.Weeks = append(calendar.Weeks, week)
+	}
+	return calendar
+}
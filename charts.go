@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+)
+
+// Chart dimensions in view box units.
+const (
+	barWidth     = 640
+	barHeight    = 240
+	barLeft      = 40
+	barTop       = 10
+	barBottom    = 30
+	donutSize    = 240
+	donutOuter   = 100
+	donutInner   = 60
+	heatLeft     = 40
+	heatTop      = 20
+	heatCell     = 24
+	heatRow      = 20
+	maxDayLabels = 14
+)
+
+const (
+	gridColor  = "#cccccc"
+	heatColor  = "#228855"
+	emptyColor = "#eeeeee"
+)
+
+// chartPalette colors the projects without a color of their own.
+var chartPalette = []string{"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f", "#edc948", "#b07aa1", "#ff9da7"}
+
+// buildCharts returns the charts of records clipped to the days from start to end.
+// Colors are the colors of projects; projects without one are given a color from
+// chartPalette.
+func buildCharts(
+	records []models.Record,
+	colors map[string]string,
+	start, end time.Time,
+	location *time.Location,
+) models.Charts {
+	worked := map[string]time.Duration{}
+	for _, record := range records {
+		worked[record.Project] += record.Duration()
+	}
+	projects := slices.DeleteFunc(slices.Sorted(maps.Keys(worked)), func(project string) bool {
+		return worked[project] <= 0
+	})
+	if len(projects) == 0 {
+		return models.Charts{}
+	}
+	palette := map[string]string{}
+	for i, project := range projects {
+		palette[project] = colors[project]
+		if palette[project] == "" {
+			palette[project] = chartPalette[i%len(chartPalette)]
+		}
+	}
+	return models.Charts{
+		Days:    dayChart(records, projects, palette, start, end, location),
+		Share:   shareChart(worked, projects, palette),
+		Heatmap: heatmapChart(records, location),
+	}
+}
+
+// weekCharts returns the charts of the time the user has worked this week.
+func weekCharts(user models.User) models.Charts {
+	location := user.Location()
+	now := time.Now()
+	start := models.PeriodStart(models.PeriodWeek, now.In(location))
+	end := models.PeriodEnd(models.PeriodWeek, now.In(location))
+	records, err := database.GetAllRecordsForUser(user.Username)
+	if err != nil {
+		return models.Charts{}
+	}
+	projects, err := database.GetAllProjects()
+	if err != nil {
+		return models.Charts{}
+	}
+	colors := map[string]string{}
+	for _, project := range projects {
+		colors[project.Name] = project.Color
+	}
+	week := []models.Record{}
+	for _, record := range records {
+		if record.End.IsZero() {
+			record.End = now
+		}
+		if record.Clip(start, end) {
+			week = append(week, record)
+		}
+	}
+	return buildCharts(week, colors, start, end, location)
+}
+
+// dayChart returns a bar for each day from start to end stacking the time of each
+// project.
+func dayChart(
+	records []models.Record,
+	projects []string,
+	palette map[string]string,
+	start, end time.Time,
+	location *time.Location,
+) *models.Chart {
+	days := []time.Time{}
+	for day := startOfDayIn(start, location); day.Before(end); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	worked := map[string]map[string]time.Duration{}
+	totals := map[string]time.Duration{}
+	for _, record := range records {
+		for _, part := range splitRecord(record, models.GroupDay, location) {
+			day := part.Start.In(location).Format("2006-01-02")
+			if worked[day] == nil {
+				worked[day] = map[string]time.Duration{}
+			}
+			worked[day][part.Project] += part.Duration()
+			totals[day] += part.Duration()
+		}
+	}
+	hours := math.Max(1, math.Ceil(slices.Max(append(slices.Collect(maps.Values(totals)), 0)).Hours()))
+	chart := &models.Chart{Title: "Time per day by project", Width: barWidth, Height: barHeight}
+	plot := float64(barHeight - barTop - barBottom)
+	slot := float64(barWidth-barLeft) / float64(len(days))
+	every := (len(days) + maxDayLabels - 1) / maxDayLabels
+	chart.Labels = append(chart.Labels,
+		models.ChartLabel{X: barLeft - 4, Y: barTop + 10, Text: fmt.Sprintf("%gh", hours), Anchor: "end"},
+		models.ChartLabel{X: barLeft - 4, Y: barTop + plot, Text: "0h", Anchor: "end"},
+	)
+	chart.Rects = append(chart.Rects,
+		models.ChartRect{X: barLeft, Y: barTop, Width: barWidth - barLeft, Height: 1, Color: gridColor, Opacity: 1},
+		models.ChartRect{X: barLeft, Y: barTop + plot, Width: barWidth - barLeft, Height: 1, Color: gridColor, Opacity: 1},
+	)
+	for i, day := range days {
+		key := day.Format("2006-01-02")
+		x := barLeft + slot*float64(i)
+		y := barTop + plot
+		for _, project := range projects {
+			d := worked[key][project]
+			if d <= 0 {
+				continue
+			}
+			height := plot * d.Hours() / hours
+			y -= height
+			chart.Rects = append(chart.Rects, models.ChartRect{
+				X: chartNumber(x + slot*0.15), Y: chartNumber(y), Width: chartNumber(slot * 0.7),
+				Height: chartNumber(height), Color: palette[project], Opacity: 1,
+				Title: fmt.Sprintf("%s %s %s", day.Format("Mon Jan 02"), project, models.FmtClock(d)),
+			})
+		}
+		if i%every == 0 {
+			chart.Labels = append(chart.Labels, models.ChartLabel{
+				X: chartNumber(x + slot/2), Y: barHeight - 10, Text: day.Format("Jan 02"), Anchor: "middle",
+			})
+		}
+	}
+	chart.Legend = chartLegend(projects, palette)
+	return chart
+}
+
+// shareChart returns a donut of the share of the time of each project.
+func shareChart(worked map[string]time.Duration, projects []string, palette map[string]string) *models.Chart {
+	var total time.Duration
+	for _, project := range projects {
+		total += worked[project]
+	}
+	chart := &models.Chart{Title: "Share of time by project", Width: donutSize, Height: donutSize}
+	angle := 0.0
+	for _, project := range projects {
+		share := float64(worked[project]) / float64(total)
+		chart.Paths = append(chart.Paths, models.ChartPath{
+			D:     donutSlice(angle, angle+share*2*math.Pi),
+			Color: palette[project],
+			Title: fmt.Sprintf("%s %s (%.0f%%)", project, models.FmtClock(worked[project]), share*100),
+		})
+		angle += share * 2 * math.Pi
+	}
+	chart.Labels = []models.ChartLabel{{
+		X: donutSize / 2, Y: donutSize/2 + 5, Text: models.FmtClock(total), Anchor: "middle",
+	}}
+	chart.Legend = chartLegend(projects, palette)
+	return chart
+}
+
+// donutSlice returns the path of the slice of a donut between two angles, measured
+// clockwise in radians from the top.  A whole donut is drawn as two rings which are
+// filled using the even-odd rule.
+func donutSlice(from, to float64) string {
+	center := float64(donutSize) / 2
+	if to-from >= 2*math.Pi-1e-9 {
+		return fmt.Sprintf("M %.2f %.2f A %d %d 0 1 1 %.2f %.2f A %d %d 0 1 1 %.2f %.2f Z "+
+			"M %.2f %.2f A %d %d 0 1 1 %.2f %.2f A %d %d 0 1 1 %.2f %.2f Z",
+			center, center-donutOuter, donutOuter, donutOuter, center, center+donutOuter,
+			donutOuter, donutOuter, center, center-donutOuter,
+			center, center-donutInner, donutInner, donutInner, center, center+donutInner,
+			donutInner, donutInner, center, center-donutInner)
+	}
+	point := func(radius, angle float64) (float64, float64) {
+		return center + radius*math.Sin(angle), center - radius*math.Cos(angle)
+	}
+	large := 0
+	if to-from > math.Pi {
+		large = 1
+	}
+	x1, y1 := point(donutOuter, from)
+	x2, y2 := point(donutOuter, to)
+	x3, y3 := point(donutInner, to)
+	x4, y4 := point(donutInner, from)
+	return fmt.Sprintf("M %.2f %.2f A %d %d 0 %d 1 %.2f %.2f L %.2f %.2f A %d %d 0 %d 0 %.2f %.2f Z",
+		x1, y1, donutOuter, donutOuter, large, x2, y2, x3, y3, donutInner, donutInner, large, x4, y4)
+}
+
+// heatmapChart returns a grid of the time worked in each hour of each weekday, in the
+// time zone of location.  The darker a cell, the more time was worked.
+func heatmapChart(records []models.Record, location *time.Location) *models.Chart {
+	var worked [7][24]time.Duration
+	for _, record := range records {
+		start := record.Start.In(location)
+		for start.Before(record.End) {
+			// step in absolute time to the next hour on the clock, which always moves
+			// forward, even through the hour repeated when daylight saving time ends
+			past := time.Duration(start.Minute())*time.Minute + time.Duration(start.Second())*time.Second +
+				time.Duration(start.Nanosecond())
+			next := start.Add(time.Hour - past)
+			if next.After(record.End) {
+				next = record.End.In(location)
+			}
+			// weeks start on Monday
+			worked[(int(start.Weekday())+6)%7][start.Hour()] += next.Sub(start)
+			start = next
+		}
+	}
+	var most time.Duration
+	for _, day := range worked {
+		most = max(most, slices.Max(day[:]))
+	}
+	chart := &models.Chart{
+		Title:  "Time by weekday and hour",
+		Width:  heatLeft + 24*heatCell,
+		Height: heatTop + 7*heatRow,
+	}
+	monday := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for day := range worked {
+		chart.Labels = append(chart.Labels, models.ChartLabel{
+			X: heatLeft - 4, Y: float64(heatTop + day*heatRow + 14), Text: monday.AddDate(0, 0, day).Format("Mon"),
+			Anchor: "end",
+		})
+		for hour, d := range worked[day] {
+			cell := models.ChartRect{
+				X: float64(heatLeft + hour*heatCell + 1), Y: float64(heatTop + day*heatRow + 1),
+				Width: heatCell - 2, Height: heatRow - 2, Color: emptyColor, Opacity: 1,
+				Title: fmt.Sprintf("%s %02d:00 %s", monday.AddDate(0, 0, day).Format("Mon"), hour, models.FmtClock(d)),
+			}
+			if d > 0 {
+				cell.Color = heatColor
+				cell.Opacity = chartNumber(0.15 + 0.85*float64(d)/float64(most))
+			}
+			chart.Rects = append(chart.Rects, cell)
+		}
+	}
+	for hour := 0; hour < 24; hour += 3 {
+		chart.Labels = append(chart.Labels, models.ChartLabel{
+			X: float64(heatLeft + hour*heatCell + heatCell/2), Y: heatTop - 6, Text: fmt.Sprintf("%02d", hour),
+			Anchor: "middle",
+		})
+	}
+	return chart
+}
+
+// chartLegend returns the legend of the projects of a chart.
+func chartLegend(projects []string, palette map[string]string) []models.ChartLegend {
+	legend := []models.ChartLegend{}
+	for _, project := range projects {
+		legend = append(legend, models.ChartLegend{Color: palette[project], Text: project})
+	}
+	return legend
+}
+
+// startOfDayIn returns midnight at the start of the day of t in location.
+func startOfDayIn(t time.Time, location *time.Location) time.Time {
+	year, month, day := t.In(location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
+
+// chartNumber rounds a coordinate to two decimal places to keep the SVG small.
+func chartNumber(f float64) float64 {
+	return math.Round(f*100) / 100
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+func TestBuildCharts(t *testing.T) {
+	// Monday
+	start := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	records := []models.Record{
+		{Project: "web", Start: start.Add(9 * time.Hour), End: start.Add(12 * time.Hour)},
+		{Project: "ops", Start: start.Add(23 * time.Hour), End: start.Add(25 * time.Hour)},
+		{Project: "idle", Start: start.Add(13 * time.Hour), End: start.Add(13 * time.Hour)},
+	}
+	t.Run("empty", func(t *testing.T) {
+		charts := buildCharts(records[2:], nil, start, end, time.UTC)
+		should.BeNil(t, charts.Days)
+		should.BeNil(t, charts.Share)
+		should.BeNil(t, charts.Heatmap)
+	})
+	charts := buildCharts(records, map[string]string{"web": "#123456"}, start, end, time.UTC)
+	t.Run("days", func(t *testing.T) {
+		bars := []models.ChartRect{}
+		for _, rect := range charts.Days.Rects {
+			if rect.Title != "" {
+				bars = append(bars, rect)
+			}
+		}
+		// ops runs past midnight so it has a bar on Monday and Tuesday
+		should.BeEqual(t, len(bars), 3)
+		should.ContainSubstring(t, bars[0].Title, "Mon Jun 01 ops")
+		should.ContainSubstring(t, bars[1].Title, "Mon Jun 01 web")
+		should.BeEqual(t, bars[1].Color, "#123456")
+		should.ContainSubstring(t, bars[2].Title, "Tue Jun 02 ops")
+		// web sits on top of ops; Monday's four hours fill the chart
+		should.BeEqual(t, bars[1].Y, float64(barTop))
+		should.BeEqual(t, len(charts.Days.Legend), 2)
+		should.BeEqual(t, charts.Days.Legend[0].Text, "ops")
+		should.BeEqual(t, charts.Days.Legend[0].Color, chartPalette[0])
+	})
+	t.Run("share", func(t *testing.T) {
+		should.BeEqual(t, len(charts.Share.Paths), 2)
+		should.ContainSubstring(t, charts.Share.Paths[0].Title, "(40%)")
+		should.ContainSubstring(t, charts.Share.Paths[1].Title, "(60%)")
+		// web covers more than half the donut so takes the large arc
+		should.ContainSubstring(t, charts.Share.Paths[1].D, " 0 1 1 ")
+		whole := buildCharts(records[:1], nil, start, end, time.UTC)
+		should.BeEqual(t, strings.Count(whole.Share.Paths[0].D, "M "), 2)
+	})
+	t.Run("heatmap", func(t *testing.T) {
+		should.BeEqual(t, len(charts.Heatmap.Rects), 7*24)
+		cell := func(day, hour int) models.ChartRect { return charts.Heatmap.Rects[day*24+hour] }
+		should.BeEqual(t, cell(0, 9).Color, heatColor)
+		should.BeEqual(t, cell(0, 9).Opacity, 1.0)
+		should.BeEqual(t, cell(0, 12).Color, emptyColor)
+		should.BeEqual(t, cell(0, 23).Color, heatColor)
+		should.BeEqual(t, cell(1, 0).Color, heatColor)
+		should.ContainSubstring(t, cell(1, 0).Title, "Tue 00:00")
+	})
+}
+
+func TestHeatmapDaylightSaving(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	should.BeNil(t, err)
+	// 00:30 EDT to 02:30 EST on Sunday 2025-11-02 covers the repeated 01:00 hour
+	start := time.Date(2025, 11, 2, 0, 30, 0, 0, location)
+	record := models.Record{Project: "web", Start: start, End: start.Add(3 * time.Hour)}
+	chart := heatmapChart([]models.Record{record}, location)
+	cell := func(hour int) models.ChartRect { return chart.Rects[6*24+hour] }
+	should.ContainSubstring(t, cell(0).Title, "00:30")
+	should.ContainSubstring(t, cell(1).Title, "02:00")
+	should.ContainSubstring(t, cell(2).Title, "00:30")
+	// half hour time zones bucket by their own clock hours
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	should.BeNil(t, err)
+	start = time.Date(2025, 11, 3, 9, 0, 0, 0, kolkata)
+	chart = heatmapChart([]models.Record{{Start: start, End: start.Add(time.Hour)}}, kolkata)
+	should.ContainSubstring(t, chart.Rects[9].Title, "01:00")
+	should.ContainSubstring(t, chart.Rects[10].Title, "00:00")
+}
+
+func TestChartPages(t *testing.T) {
+	deleteAllUsers()
+	deleteAllRecords()
+	deleteAllProjects()
+	createTestProjects()
+	user := models.User{Username: "test", Password: "testing"}
+	should.BeNil(t, createTestUser(user))
+	should.BeNil(t, database.SaveRecord(&models.Record{
+		ID:      uuid.New(),
+		Project: "test",
+		User:    "test",
+		Start:   time.Now().Add(-time.Hour),
+		End:     time.Now(),
+	}))
+	t.Run("status", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/status/", nil)
+		req.AddCookie(testLogin(user))
+		router.ServeHTTP(w, req)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "This week")
+		should.ContainSubstring(t, w.Body.String(), `<svg viewBox="0 0 640 240"`)
+	})
+	t.Run("report", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		payload := bodyParams(
+			"start", time.Now().AddDate(0, 0, -6).Format("2006-01-02"),
+			"end", time.Now().Format("2006-01-02"),
+		)
+		req := httptest.NewRequest(http.MethodPost, "/reports/", payload)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(testLogin(user))
+		router.ServeHTTP(w, req)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		body := w.Body.String()
+		should.ContainSubstring(t, body, "Time per day by project")
+		should.ContainSubstring(t, body, "Share of time by project")
+		should.ContainSubstring(t, body, "Time by weekday and hour")
+		should.ContainSubstring(t, body, `fill-rule="evenodd"`)
+	})
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+func getClients(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to view clients")
+		return
+	}
+	clients, err := database.GetAllClients()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	render(w, "clients", clients)
+}
+
+func displayClientForm(w http.ResponseWriter, _ *http.Request) {
+	render(w, "editClient", models.Client{})
+}
+
+func getClient(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to view clients")
+		return
+	}
+	client, err := database.GetClient(r.PathValue("name"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	render(w, "editClient", client)
+}
+
+func saveClient(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to edit clients")
+		return
+	}
+	client := models.Client{
+		Name:     strings.TrimSpace(r.FormValue("name")),
+		Contact:  strings.TrimSpace(r.FormValue("contact")),
+		Currency: strings.ToUpper(strings.TrimSpace(r.FormValue("currency"))),
+		Updated:  time.Now(),
+	}
+	if client.Name == "" {
+		processError(w, http.StatusBadRequest, "invalid client name")
+		return
+	}
+	if rate := r.FormValue("rate"); rate != "" {
+		var err error
+		client.Rate, err = models.ParseMoney(rate)
+		if err != nil {
+			processError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	rounding, err := parseRounding(r)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	client.Rounding = rounding
+	existing, err := database.GetClient(client.Name)
+	if err == nil {
+		client.ID = existing.ID
+	} else {
+		client.ID = uuid.New()
+	}
+	if err := database.SaveClient(&client); err != nil {
+		processError(w, http.StatusInternalServerError, "error saving client "+err.Error())
+		return
+	}
+	slog.Info("client saved", "client", client.Name)
+	getClients(w, r)
+}
+
+func deleteClient(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	name := r.PathValue("name")
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to delete clients")
+		return
+	}
+	if _, err := database.GetClient(name); err != nil {
+		processError(w, http.StatusBadRequest, "client does not exist")
+		return
+	}
+	projects, err := database.GetAllProjects()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, project := range projects {
+		if project.Client == name {
+			processError(w, http.StatusBadRequest, "client has projects")
+			return
+		}
+	}
+	if err := database.DeleteClient(name); err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	slog.Info("deleted", "client", name)
+	getClients(w, r)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+func TestClients(t *testing.T) {
+	deleteAllClients()
+	deleteAllProjects()
+	createTestProjects()
+	deleteAllUsers()
+	createAdmin()
+	should.BeNil(t, createTestUser(models.User{Username: "test", Password: "testing"}))
+	request := func(cookie *http.Cookie, method, url string, params ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, bodyParams(params...))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	t.Run("form", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodGet, "/clients/new/")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "New Client")
+	})
+	t.Run("notAdmin", func(t *testing.T) {
+		cookie := testLogin(models.User{Username: "test", Password: "testing"})
+		w := request(cookie, http.MethodPost, "/clients/", "name", "ACME")
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+		w = request(cookie, http.MethodGet, "/clients/")
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+		w = request(cookie, http.MethodGet, "/clients/ACME")
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodPost, "/clients/", "name", " ")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		w = request(adminLogin(), http.MethodPost, "/clients/", "name", "ACME", "rate", "lots")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+	t.Run("save", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodPost, "/clients/",
+			"name", "ACME", "contact", "wile@acme.com", "rate", "90", "currency", "cad")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "90.00 CAD")
+		client, err := database.GetClient("ACME")
+		should.BeNil(t, err)
+		w = request(adminLogin(), http.MethodPost, "/clients/",
+			"name", "ACME", "rate", "95", "currency", "CAD")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		updated, err := database.GetClient("ACME")
+		should.BeNil(t, err)
+		should.BeEqual(t, updated.ID, client.ID)
+		should.BeEqual(t, updated.Rate, int64(9500))
+	})
+	t.Run("edit", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodGet, "/clients/ACME")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "Edit Client")
+		w = request(adminLogin(), http.MethodGet, "/clients/missing")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+	t.Run("assignProject", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodPost, "/projects/edit/test", "client", "missing")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		w = request(adminLogin(), http.MethodPost, "/projects/edit/test", "client", "ACME")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		w = request(adminLogin(), http.MethodGet, "/projects/list/")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "<h2>ACME</h2>")
+	})
+	t.Run("report", func(t *testing.T) {
+		deleteAllRecords()
+		_ = database.SaveRecord(&models.Record{
+			ID:       uuid.New(),
+			Project:  "test",
+			User:     "admin",
+			Start:    time.Now().Add(-2 * time.Hour),
+			End:      time.Now().Add(-time.Hour),
+			Billable: true,
+		})
+		_ = database.SaveRecord(&models.Record{
+			ID:      uuid.New(),
+			Project: "test2",
+			User:    "admin",
+			Start:   time.Now().Add(-4 * time.Hour),
+			End:     time.Now().Add(-3 * time.Hour),
+		})
+		today := time.Now().Format(time.DateOnly)
+		w := request(adminLogin(), http.MethodPost, "/reports/", "start", today, "end", today)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "Client Totals")
+		should.ContainSubstring(t, w.Body.String(), "95.00 CAD")
+		should.ContainSubstring(t, w.Body.String(), "Project test2")
+		w = request(adminLogin(), http.MethodPost, "/reports/",
+			"start", today, "end", today, "client", "ACME")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "Project test (ACME)")
+		should.BeFalse(t, strings.Contains(w.Body.String(), "Project test2"))
+		deleteAllRecords()
+	})
+	t.Run("deleteWithProjects", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodDelete, "/clients/ACME")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "client has projects")
+	})
+	t.Run("delete", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodPost, "/projects/edit/test", "client", "")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		w = request(adminLogin(), http.MethodDelete, "/clients/ACME")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		w = request(adminLogin(), http.MethodDelete, "/clients/ACME")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+}
+
+func TestGroupProjects(t *testing.T) {
+	groups := groupProjects([]models.Project{
+		{Name: "one"},
+		{Name: "two", Client: "b"},
+		{Name: "three", Client: "a"},
+		{Name: "four", Client: "b"},
+	})
+	should.BeEqual(t, groups, []models.ProjectGroup{
+		{Client: "a", Projects: []models.Project{{Name: "three", Client: "a"}}},
+		{Client: "b", Projects: []models.Project{
+			{Name: "two", Client: "b"},
+			{Name: "four", Client: "b"},
+		}},
+		{Projects: []models.Project{{Name: "one"}}},
+	})
+}
+
+func deleteAllClients() {
+	clients, _ := database.GetAllClients()
+	for _, client := range clients {
+		_ = database.DeleteClient(client.Name)
+	}
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+	"go.etcd.io/bbolt"
+)
+
+// SaveAbsence saves an absence to db.
+func SaveAbsence(a *models.Absence) error {
+	value, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	return db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(absenceTableName))
+		return b.Put([]byte(a.ID.String()), value)
+	})
+}
+
+// GetAbsence retrieves an absence from db.
+func GetAbsence(id uuid.UUID) (models.Absence, error) {
+	absence := models.Absence{}
+	if err := db.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket([]byte(absenceTableName)).Get([]byte(id.String()))
+		if v == nil {
+			return errors.New("no such absence")
+		}
+		return json.Unmarshal(v, &absence)
+	}); err != nil {
+		return absence, err
+	}
+	return absence, nil
+}
+
+// GetAllAbsences retrieves all absences from db.
+func GetAllAbsences() ([]models.Absence, error) {
+	var absences []models.Absence
+	if err := db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(absenceTableName))
+		return b.ForEach(func(_, v []byte) error {
+			absence := models.Absence{}
+			if err := json.Unmarshal(v, &absence); err != nil {
+				return err
+			}
+			absences = append(absences, absence)
+			return nil
+		})
+	}); err != nil {
+		return absences, err
+	}
+	return absences, nil
+}
+
+// GetAbsencesForUser retrieves all absences of a user from db.
+func GetAbsencesForUser(user string) ([]models.Absence, error) {
+	absences := []models.Absence{}
+	all, err := GetAllAbsences()
+	if err != nil {
+		return absences, err
+	}
+	for _, absence := range all {
+		if absence.User == user {
+			absences = append(absences, absence)
+		}
+	}
+	return absences, nil
+}
+
+// DeleteAbsence deletes an absence from the db.
+func DeleteAbsence(id uuid.UUID) error {
+	return db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(absenceTableName)).Delete([]byte(id.String()))
+	})
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+func TestAbsences(t *testing.T) {
+	absences, err := GetAllAbsences()
+	should.BeNil(t, err)
+	for _, absence := range absences {
+		should.BeNil(t, DeleteAbsence(absence.ID))
+	}
+	id := uuid.New()
+	t.Run("save", func(t *testing.T) {
+		should.BeNil(t, SaveAbsence(&models.Absence{
+			ID:    id,
+			User:  "test",
+			Kind:  models.AbsenceVacation,
+			Start: time.Now(),
+			End:   time.Now().AddDate(0, 0, 2),
+		}))
+		should.BeNil(t, SaveAbsence(&models.Absence{
+			ID:    uuid.New(),
+			User:  "other",
+			Kind:  models.AbsenceSick,
+			Start: time.Now(),
+			End:   time.Now(),
+		}))
+	})
+	t.Run("get", func(t *testing.T) {
+		absence, err := GetAbsence(id)
+		should.BeNil(t, err)
+		should.BeEqual(t, absence.Kind, models.AbsenceVacation)
+		_, err = GetAbsence(uuid.New())
+		should.NotBeNil(t, err)
+	})
+	t.Run("user", func(t *testing.T) {
+		absences, err := GetAbsencesForUser("test")
+		should.BeNil(t, err)
+		should.BeEqual(t, len(absences), 1)
+	})
+	t.Run("delete", func(t *testing.T) {
+		should.BeNil(t, DeleteAbsence(id))
+		absences, err := GetAllAbsences()
+		should.BeNil(t, err)
+		should.BeEqual(t, len(absences), 1)
+	})
+}
+
+func TestHolidayCalendars(t *testing.T) {
+	calendars, err := GetAllHolidayCalendars()
+	should.BeNil(t, err)
+	for _, calendar := range calendars {
+		should.BeNil(t, DeleteHolidayCalendar(calendar.Name))
+	}
+	t.Run("save", func(t *testing.T) {
+		should.BeNil(t, SaveHolidayCalendar(&models.HolidayCalendar{
+			Name:     "Canada",
+			Holidays: []models.Holiday{{Date: time.Date(2026, 7, 1, 0, 0, 0, 0, time.Local), Name: "Canada Day"}},
+		}))
+	})
+	t.Run("get", func(t *testing.T) {
+		calendar, err := GetHolidayCalendar("Canada")
+		should.BeNil(t, err)
+		should.BeEqual(t, calendar.Holidays[0].Name, "Canada Day")
+		_, err = GetHolidayCalendar("missing")
+		should.NotBeNil(t, err)
+	})
+	t.Run("delete", func(t *testing.T) {
+		should.BeNil(t, DeleteHolidayCalendar("Canada"))
+		calendars, err := GetAllHolidayCalendars()
+		should.BeNil(t, err)
+		should.BeEmpty(t, calendars)
+	})
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/devilcove/timetraced/models"
+	"go.etcd.io/bbolt"
+)
+
+// SaveClient saves a client to db.
+func SaveClient(c *models.Client) error {
+	value, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(clientTableName))
+		return b.Put([]byte(c.Name), value)
+	})
+}
+
+// GetClient retrieves a client from db.
+func GetClient(name string) (models.Client, error) {
+	client := models.Client{}
+	if err := db.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket([]byte(clientTableName)).Get([]byte(name))
+		if v == nil {
+			return errors.New("no such client")
+		}
+		return json.Unmarshal(v, &client)
+	}); err != nil {
+		return client, err
+	}
+	return client, nil
+}
+
+// GetAllClients retrieves all clients from db.
+func GetAllClients() ([]models.Client, error) {
+	var clients []models.Client
+	if err := db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(clientTableName))
+		return b.ForEach(func(_, v []byte) error {
+			client := models.Client{}
+			if err := json.Unmarshal(v, &client); err != nil {
+				return err
+			}
+			clients = append(clients, client)
+			return nil
+		})
+	}); err != nil {
+		return clients, err
+	}
+	return clients, nil
+}
+
+// DeleteClient deletes a client from the db.
+func DeleteClient(name string) error {
+	return db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(clientTableName)).Delete([]byte(name))
+	})
+}
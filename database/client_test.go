@@ -0,0 +1,45 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+func TestClients(t *testing.T) {
+	clients, err := GetAllClients()
+	should.BeNil(t, err)
+	for _, client := range clients {
+		should.BeNil(t, DeleteClient(client.Name))
+	}
+	t.Run("save", func(t *testing.T) {
+		should.BeNil(t, SaveClient(&models.Client{
+			ID:       uuid.New(),
+			Name:     "ACME Corp",
+			Rate:     10000,
+			Currency: "CAD",
+			Updated:  time.Now(),
+		}))
+	})
+	t.Run("get", func(t *testing.T) {
+		client, err := GetClient("ACME Corp")
+		should.BeNil(t, err)
+		should.BeEqual(t, client.Currency, "CAD")
+		_, err = GetClient("missing")
+		should.NotBeNil(t, err)
+	})
+	t.Run("all", func(t *testing.T) {
+		clients, err := GetAllClients()
+		should.BeNil(t, err)
+		should.BeEqual(t, len(clients), 1)
+	})
+	t.Run("delete", func(t *testing.T) {
+		should.BeNil(t, DeleteClient("ACME Corp"))
+		clients, err := GetAllClients()
+		should.BeNil(t, err)
+		should.BeEmpty(t, clients)
+	})
+}
@@ -13,12 +13,19 @@ const (
 	userTableName    = "users"
 	projectTableName = "projects"
 	recordsTableName = "records"
+	tagTableName     = "tags"
+	invoiceTableName = "invoices"
+	clientTableName  = "clients"
+	absenceTableName = "absences"
+	holidayTableName = "holidays"
 )
 
 var (
 	// ErrNoResults is returned when a db record does not exist in db.
 	ErrNoResults = errors.New("no results found")
-	db           *bbolt.DB
+	// ErrRecordInvoiced is returned when modifying a record included in an invoice.
+	ErrRecordInvoiced = errors.New("record has been invoiced")
+	db                *bbolt.DB
 )
 
 // InitializeDatabase opens (creates if it does not exist) the db and creates any non-exitent tables.
@@ -52,6 +59,21 @@ func createTables() error {
 	if err := createTable(recordsTableName); err != nil {
 		return err
 	}
+	if err := createTable(tagTableName); err != nil {
+		return err
+	}
+	if err := createTable(invoiceTableName); err != nil {
+		return err
+	}
+	if err := createTable(clientTableName); err != nil {
+		return err
+	}
+	if err := createTable(absenceTableName); err != nil {
+		return err
+	}
+	if err := createTable(holidayTableName); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/devilcove/timetraced/models"
+	"go.etcd.io/bbolt"
+)
+
+// SaveHolidayCalendar saves a holiday calendar to db.
+func SaveHolidayCalendar(c *models.HolidayCalendar) error {
+	value, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(holidayTableName))
+		return b.Put([]byte(c.Name), value)
+	})
+}
+
+// GetHolidayCalendar retrieves a holiday calendar from db.
+func GetHolidayCalendar(name string) (models.HolidayCalendar, error) {
+	calendar := models.HolidayCalendar{}
+	if err := db.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket([]byte(holidayTableName)).Get([]byte(name))
+		if v == nil {
+			return errors.New("no such holiday calendar")
+		}
+		return json.Unmarshal(v, &calendar)
+	}); err != nil {
+		return calendar, err
+	}
+	return calendar, nil
+}
+
+// GetAllHolidayCalendars retrieves all holiday calendars from db.
+func GetAllHolidayCalendars() ([]models.HolidayCalendar, error) {
+	var calendars []models.HolidayCalendar
+	if err := db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(holidayTableName))
+		return b.ForEach(func(_, v []byte) error {
+			calendar := models.HolidayCalendar{}
+			if err := json.Unmarshal(v, &calendar); err != nil {
+				return err
+			}
+			calendars = append(calendars, calendar)
+			return nil
+		})
+	}); err != nil {
+		return calendars, err
+	}
+	return calendars, nil
+}
+
+// DeleteHolidayCalendar deletes a holiday calendar from the db.
+func DeleteHolidayCalendar(name string) error {
+	return db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(holidayTableName)).Delete([]byte(name))
+	})
+}
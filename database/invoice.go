@@ -0,0 +1,97 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+	"go.etcd.io/bbolt"
+)
+
+// SaveInvoice saves an invoice to the db, assigning the next invoice number to a new
+// invoice.  In the same transaction the records included in the invoice are marked
+// as invoiced or, if the invoice has been voided, released for editing.
+func SaveInvoice(i *models.Invoice) error {
+	return db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(invoiceTableName))
+		if i.Number == 0 {
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			i.Number = seq
+		}
+		value, err := json.Marshal(i)
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte(i.ID.String()), value); err != nil {
+			return err
+		}
+		records := tx.Bucket([]byte(recordsTableName))
+		for _, id := range i.Records {
+			v := records.Get([]byte(id.String()))
+			if v == nil {
+				return errors.New("no such record " + id.String())
+			}
+			record := models.Record{}
+			if err := json.Unmarshal(v, &record); err != nil {
+				return err
+			}
+			switch {
+			case i.Voided && record.Invoice != i.ID:
+				// the record has been invoiced again since
+				continue
+			case i.Voided:
+				record.Invoice = uuid.Nil
+			case record.Invoiced() && record.Invoice != i.ID:
+				return ErrRecordInvoiced
+			default:
+				record.Invoice = i.ID
+			}
+			value, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			if err := records.Put([]byte(id.String()), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetInvoice retrieves an invoice from db.
+func GetInvoice(id uuid.UUID) (models.Invoice, error) {
+	invoice := models.Invoice{}
+	if err := db.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket([]byte(invoiceTableName)).Get([]byte(id.String()))
+		if v == nil {
+			return errors.New("no such invoice")
+		}
+		return json.Unmarshal(v, &invoice)
+	}); err != nil {
+		return invoice, err
+	}
+	return invoice, nil
+}
+
+// GetAllInvoices retrieves all invoices from db.
+func GetAllInvoices() ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	if err := db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(invoiceTableName))
+		return b.ForEach(func(_, v []byte) error {
+			invoice := models.Invoice{}
+			if err := json.Unmarshal(v, &invoice); err != nil {
+				return err
+			}
+			invoices = append(invoices, invoice)
+			return nil
+		})
+	}); err != nil {
+		return invoices, err
+	}
+	return invoices, nil
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+func TestInvoice(t *testing.T) {
+	should.BeNil(t, deleteAllRecords())
+	should.BeNil(t, createTestRecords())
+	records, err := GetAllRecords()
+	should.BeNil(t, err)
+	invoice := models.Invoice{
+		ID:      uuid.New(),
+		Client:  "client",
+		Records: []uuid.UUID{records[0].ID},
+		Created: time.Now(),
+	}
+	t.Run("save", func(t *testing.T) {
+		should.BeNil(t, SaveInvoice(&invoice))
+		should.NotBeEqual(t, invoice.Number, uint64(0))
+		record, err := GetRecord(records[0].ID)
+		should.BeNil(t, err)
+		should.BeEqual(t, record.Invoice, invoice.ID)
+	})
+	t.Run("sequence", func(t *testing.T) {
+		next := models.Invoice{ID: uuid.New(), Client: "client"}
+		should.BeNil(t, SaveInvoice(&next))
+		should.BeEqual(t, next.Number, invoice.Number+1)
+	})
+	t.Run("alreadyInvoiced", func(t *testing.T) {
+		other := models.Invoice{ID: uuid.New(), Records: []uuid.UUID{records[0].ID}}
+		should.BeEqual(t, SaveInvoice(&other), ErrRecordInvoiced)
+	})
+	t.Run("missingRecord", func(t *testing.T) {
+		other := models.Invoice{ID: uuid.New(), Records: []uuid.UUID{uuid.New()}}
+		should.NotBeNil(t, SaveInvoice(&other))
+	})
+	t.Run("delete", func(t *testing.T) {
+		should.BeEqual(t, DeleteRecord(records[0].ID), ErrRecordInvoiced)
+	})
+	t.Run("get", func(t *testing.T) {
+		saved, err := GetInvoice(invoice.ID)
+		should.BeNil(t, err)
+		should.BeEqual(t, saved.Number, invoice.Number)
+		_, err = GetInvoice(uuid.New())
+		should.NotBeNil(t, err)
+		invoices, err := GetAllInvoices()
+		should.BeNil(t, err)
+		should.BeGreaterThan(t, len(invoices), 1)
+	})
+	t.Run("void", func(t *testing.T) {
+		invoice.Voided = true
+		should.BeNil(t, SaveInvoice(&invoice))
+		record, err := GetRecord(records[0].ID)
+		should.BeNil(t, err)
+		should.BeFalse(t, record.Invoiced())
+	})
+	t.Run("reinvoiced", func(t *testing.T) {
+		again := models.Invoice{ID: uuid.New(), Records: []uuid.UUID{records[0].ID}}
+		should.BeNil(t, SaveInvoice(&again))
+		// saving the voided invoice again leaves the record on the new invoice
+		should.BeNil(t, SaveInvoice(&invoice))
+		record, err := GetRecord(records[0].ID)
+		should.BeNil(t, err)
+		should.BeEqual(t, record.Invoice, again.ID)
+		again.Voided = true
+		should.BeNil(t, SaveInvoice(&again))
+		should.BeNil(t, DeleteRecord(records[0].ID))
+	})
+}
@@ -41,10 +41,10 @@ func GetProject(name string) (models.Project, error) {
 // GetAllProjects retrieves all projects from db.
 func GetAllProjects() ([]models.Project, error) {
 	var projects []models.Project
-	var project models.Project
 	if err := db.View(func(tx *bbolt.Tx) error {
 		b := tx.Bucket([]byte(projectTableName))
 		_ = b.ForEach(func(_, v []byte) error {
+			project := models.Project{}
 			if err := json.Unmarshal(v, &project); err != nil {
 				return err
 			}
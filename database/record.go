@@ -3,6 +3,7 @@ package database
 import (
 	"encoding/json"
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/devilcove/timetraced/models"
@@ -43,10 +44,10 @@ func GetRecord(id uuid.UUID) (models.Record, error) {
 // GetAllRecords returns all records from db.
 func GetAllRecords() ([]models.Record, error) {
 	var records []models.Record
-	var record models.Record
 	if err := db.View(func(tx *bbolt.Tx) error {
 		b := tx.Bucket([]byte(recordsTableName))
 		_ = b.ForEach(func(_, v []byte) error {
+			record := models.Record{}
 			if err := json.Unmarshal(v, &record); err != nil {
 				return err
 			}
@@ -63,10 +64,10 @@ func GetAllRecords() ([]models.Record, error) {
 // GetAllRecordsForUser returns all records created by user from db.
 func GetAllRecordsForUser(u string) ([]models.Record, error) {
 	var records []models.Record
-	var record models.Record
 	if err := db.View(func(tx *bbolt.Tx) error {
 		b := tx.Bucket([]byte(recordsTableName))
 		_ = b.ForEach(func(_, v []byte) error {
+			record := models.Record{}
 			if err := json.Unmarshal(v, &record); err != nil {
 				return err
 			}
@@ -82,10 +83,20 @@ func GetAllRecordsForUser(u string) ([]models.Record, error) {
 	return records, nil
 }
 
-// DeleteRecord deletes a record from db.
+// DeleteRecord deletes a record from db.  Invoiced records cannot be deleted.
 func DeleteRecord(id uuid.UUID) error {
 	if err := db.Update(func(tx *bbolt.Tx) error {
-		return tx.Bucket([]byte(recordsTableName)).Delete([]byte(id.String()))
+		b := tx.Bucket([]byte(recordsTableName))
+		if v := b.Get([]byte(id.String())); v != nil {
+			record := models.Record{}
+			if err := json.Unmarshal(v, &record); err != nil {
+				return err
+			}
+			if record.Invoiced() {
+				return ErrRecordInvoiced
+			}
+		}
+		return b.Delete([]byte(id.String()))
 	}); err != nil {
 		return err
 	}
@@ -95,11 +106,11 @@ func DeleteRecord(id uuid.UUID) error {
 // GetTodaysRecords returns records created on this day.
 func GetTodaysRecords() ([]models.Record, error) {
 	records := []models.Record{}
-	record := models.Record{}
 	today := truncateToStart(time.Now())
 	if err := db.View(func(tx *bbolt.Tx) error {
 		b := tx.Bucket([]byte(recordsTableName))
 		_ = b.ForEach(func(_, v []byte) error {
+			record := models.Record{}
 			if err := json.Unmarshal(v, &record); err != nil {
 				return err
 			}
@@ -115,21 +126,22 @@ func GetTodaysRecords() ([]models.Record, error) {
 	return records, nil
 }
 
-// GetTodaysRecordsForUser return records created on this day by specified user.
+// GetTodaysRecordsForUser return records of the specified user which started on
+// this day or were still being tracked at its start.
 func GetTodaysRecordsForUser(user string) ([]models.Record, error) {
 	if user == "" {
 		return []models.Record{}, nil
 	}
 	records := []models.Record{}
-	record := models.Record{}
 	today := truncateToStart(time.Now())
 	if err := db.View(func(tx *bbolt.Tx) error {
 		b := tx.Bucket([]byte(recordsTableName))
 		_ = b.ForEach(func(_, v []byte) error {
+			record := models.Record{}
 			if err := json.Unmarshal(v, &record); err != nil {
 				return err
 			}
-			if record.User == user && record.Start.After(today) {
+			if record.User == user && (record.Start.After(today) || record.End.IsZero() || record.End.After(today)) {
 				records = append(records, record)
 			}
 			return nil
@@ -141,26 +153,29 @@ func GetTodaysRecordsForUser(user string) ([]models.Record, error) {
 	return records, nil
 }
 
-// GetReportRecords returns record matching the request.
+// GetReportRecords returns record matching the request.  Records overlapping the
+// start or end day of the request are clipped so that only the time within the
+// request is reported.  Records still being tracked end now.
 func GetReportRecords(req models.DatabaseReportRequest) ([]models.Record, error) {
 	records := []models.Record{}
-	record := models.Record{}
 	start := truncateToStart(req.Start)
-	end := truncateToEnd(req.End)
+	end := truncateToStart(req.End).AddDate(0, 0, 1)
 	if err := db.View(func(tx *bbolt.Tx) error {
 		b := tx.Bucket([]byte(recordsTableName))
 		_ = b.ForEach(func(_, v []byte) error {
+			record := models.Record{}
 			if err := json.Unmarshal(v, &record); err != nil {
 				return err
 			}
-			if (req.User == record.User) &&
+			if (req.User == record.User || slices.Contains(req.Users, record.User)) &&
 				(req.Project == record.Project) &&
-				record.Start.After(start) &&
-				record.Start.Before(end) {
+				(req.Tag == "" || slices.Contains(record.Tags, req.Tag)) {
 				if record.End.IsZero() {
 					record.End = time.Now()
 				}
-				records = append(records, record)
+				if record.Clip(start, end) {
+					records = append(records, record)
+				}
 			}
 			return nil
 		})
@@ -175,6 +190,58 @@ func truncateToStart(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
 }
 
-func truncateToEnd(t time.Time) time.Time {
-	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+// ReassignRecords moves all records of project from to project to, clearing their
+// tasks which belong to the original project.  No records are changed if any of
+// them has been invoiced.
+func ReassignRecords(from, to string) error {
+	return updateProjectRecords(from, func(b *bbolt.Bucket, k []byte, record models.Record) error {
+		record.Project = to
+		record.Task = ""
+		value, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		return b.Put(k, value)
+	})
+}
+
+// DeleteProjectRecords deletes all records of project.  No records are deleted if any
+// of them has been invoiced.
+func DeleteProjectRecords(project string) error {
+	return updateProjectRecords(project, func(b *bbolt.Bucket, k []byte, _ models.Record) error {
+		return b.Delete(k)
+	})
+}
+
+// updateProjectRecords applies fn to each record of project in a single transaction.
+func updateProjectRecords(
+	project string,
+	fn func(b *bbolt.Bucket, k []byte, record models.Record) error,
+) error {
+	return db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(recordsTableName))
+		matched := map[string]models.Record{}
+		if err := b.ForEach(func(k, v []byte) error {
+			record := models.Record{}
+			if err := json.Unmarshal(v, &record); err != nil {
+				return err
+			}
+			if record.Project != project {
+				return nil
+			}
+			if record.Invoiced() {
+				return ErrRecordInvoiced
+			}
+			matched[string(k)] = record
+			return nil
+		}); err != nil {
+			return err
+		}
+		for k, record := range matched {
+			if err := fn(b, []byte(k), record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
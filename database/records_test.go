@@ -35,32 +35,6 @@ func Test_truncateToStart(t *testing.T) {
 	}
 }
 
-func Test_truncateToEnd(t *testing.T) {
-	tests := []struct {
-		name string
-		args time.Time
-		want time.Time
-	}{
-		{
-			name: "UTC",
-			args: time.Date(2023, 1, 1, 17, 34, 59, 0, time.UTC),
-			want: time.Date(2023, 1, 1, 23, 59, 59, 0, time.UTC),
-		},
-		{
-			name: "local",
-			args: time.Date(1918, 10, 30, 12, 1, 9, 0, time.Local),
-			want: time.Date(1918, 10, 30, 23, 59, 59, 0, time.Local),
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := truncateToEnd(tt.args); got.Compare(tt.want) != 0 {
-				t.Errorf("truncateToStart() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestSaveRecord(t *testing.T) {
 	should.BeNil(t, deleteAllRecords())
 	err := SaveRecord(&models.Record{
@@ -122,6 +96,42 @@ func TestGetReportRecords(t *testing.T) {
 		should.BeNil(t, err)
 		should.BeEqual(t, len(records), 0)
 	})
+	t.Run("users", func(t *testing.T) {
+		records, err := GetReportRecords(models.DatabaseReportRequest{
+			Start:   time.Now(),
+			End:     time.Now(),
+			Project: "two",
+			Users:   []string{"testUser", "user1"},
+		})
+		should.BeNil(t, err)
+		should.BeEqual(t, len(records), 1)
+		should.BeEqual(t, records[0].User, "user1")
+	})
+	t.Run("straddling", func(t *testing.T) {
+		day := time.Date(2025, 6, 2, 0, 0, 0, 0, time.Local)
+		for _, record := range []models.Record{
+			{ID: uuid.New(), Project: "three", User: "testUser", Start: day.Add(-time.Hour), End: day.Add(time.Hour)},
+			{ID: uuid.New(), Project: "three", User: "testUser", Start: day.Add(23 * time.Hour), End: day.Add(26 * time.Hour)},
+			{ID: uuid.New(), Project: "three", User: "testUser", Start: day, End: day.Add(30 * time.Minute)},
+		} {
+			should.BeNil(t, SaveRecord(&record))
+		}
+		records, err := GetReportRecords(models.DatabaseReportRequest{
+			Start:   day.Add(12 * time.Hour),
+			End:     day.Add(12 * time.Hour),
+			Project: "three",
+			User:    "testUser",
+		})
+		should.BeNil(t, err)
+		should.BeEqual(t, len(records), 3)
+		var total time.Duration
+		for _, record := range records {
+			should.BeFalse(t, record.Start.Before(day))
+			should.BeFalse(t, record.End.After(day.AddDate(0, 0, 1)))
+			total += record.Duration()
+		}
+		should.BeEqual(t, total, 150*time.Minute)
+	})
 }
 
 func TestDeleteRecords(t *testing.T) {
@@ -144,6 +154,40 @@ func TestGetAllRecordsForUser(t *testing.T) {
 	should.BeEqual(t, len(records), 2)
 }
 
+func TestProjectRecords(t *testing.T) {
+	should.BeNil(t, deleteAllRecords())
+	should.BeNil(t, createTestRecords())
+	t.Run("reassign", func(t *testing.T) {
+		should.BeNil(t, ReassignRecords("one", "three"))
+		records, err := GetAllRecords()
+		should.BeNil(t, err)
+		count := 0
+		for _, record := range records {
+			should.NotBeEqual(t, record.Project, "one")
+			if record.Project == "three" {
+				count++
+			}
+		}
+		should.BeEqual(t, count, 2)
+	})
+	t.Run("invoiced", func(t *testing.T) {
+		records, err := GetAllRecordsForUser("user1")
+		should.BeNil(t, err)
+		invoice := models.Invoice{ID: uuid.New(), Records: []uuid.UUID{records[0].ID}}
+		should.BeNil(t, SaveInvoice(&invoice))
+		should.BeEqual(t, ReassignRecords("two", "three"), ErrRecordInvoiced)
+		should.BeEqual(t, DeleteProjectRecords("two"), ErrRecordInvoiced)
+		invoice.Voided = true
+		should.BeNil(t, SaveInvoice(&invoice))
+	})
+	t.Run("delete", func(t *testing.T) {
+		should.BeNil(t, DeleteProjectRecords("three"))
+		records, err := GetAllRecords()
+		should.BeNil(t, err)
+		should.BeEqual(t, len(records), 1)
+	})
+}
+
 func createTestRecords() error {
 	records := []models.Record{
 		{
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/devilcove/timetraced/models"
+	"go.etcd.io/bbolt"
+)
+
+// SaveTag saves a tag to the db.
+func SaveTag(t *models.Tag) error {
+	value, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(tagTableName))
+		return b.Put([]byte(t.Name), value)
+	})
+}
+
+// GetTag retrieves a tag from db.
+func GetTag(name string) (models.Tag, error) {
+	tag := models.Tag{}
+	if err := db.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket([]byte(tagTableName)).Get([]byte(name))
+		if v == nil {
+			return errors.New("no such tag")
+		}
+		return json.Unmarshal(v, &tag)
+	}); err != nil {
+		return tag, err
+	}
+	return tag, nil
+}
+
+// GetAllTags retrieves all tags from db, ordered by name.
+func GetAllTags() ([]models.Tag, error) {
+	var tags []models.Tag
+	if err := db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(tagTableName))
+		return b.ForEach(func(_, v []byte) error {
+			tag := models.Tag{}
+			if err := json.Unmarshal(v, &tag); err != nil {
+				return err
+			}
+			tags = append(tags, tag)
+			return nil
+		})
+	}); err != nil {
+		return tags, err
+	}
+	return tags, nil
+}
+
+// DeleteTag deletes a tag from the db.
+func DeleteTag(name string) error {
+	return db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(tagTableName)).Delete([]byte(name))
+	})
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/models"
+)
+
+func TestTags(t *testing.T) {
+	should.BeNil(t, deleteAllTags())
+	t.Run("save", func(t *testing.T) {
+		should.BeNil(t, SaveTag(&models.Tag{Name: "meeting", Updated: time.Now()}))
+		should.BeNil(t, SaveTag(&models.Tag{Name: "dev", Updated: time.Now()}))
+	})
+	t.Run("get", func(t *testing.T) {
+		tag, err := GetTag("meeting")
+		should.BeNil(t, err)
+		should.BeEqual(t, tag.Name, "meeting")
+	})
+	t.Run("missing", func(t *testing.T) {
+		_, err := GetTag("support")
+		should.NotBeNil(t, err)
+	})
+	t.Run("all", func(t *testing.T) {
+		tags, err := GetAllTags()
+		should.BeNil(t, err)
+		should.BeEqual(t, len(tags), 2)
+		should.BeEqual(t, tags[0].Name, "dev")
+	})
+	t.Run("delete", func(t *testing.T) {
+		should.BeNil(t, DeleteTag("dev"))
+		tags, err := GetAllTags()
+		should.BeNil(t, err)
+		should.BeEqual(t, len(tags), 1)
+	})
+}
+
+func deleteAllTags() error {
+	tags, err := GetAllTags()
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := DeleteTag(tag.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -41,13 +41,13 @@ func GetUser(name string) (models.User, error) {
 // GetAllUsers retrieves all users from db.
 func GetAllUsers() ([]models.User, error) {
 	var users []models.User
-	var user models.User
 	if err := db.View(func(tx *bbolt.Tx) error {
 		b := tx.Bucket([]byte(userTableName))
 		if b == nil {
 			return errors.New("no users")
 		}
 		_ = b.ForEach(func(_, v []byte) error {
+			user := models.User{}
 			if err := json.Unmarshal(v, &user); err != nil {
 				return err
 			}
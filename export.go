@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+// exportHeader is the header row of exported reports.
+var exportHeader = []string{
+	"Project", "Client", "User", "Task", "Start", "End", "Duration", "Hours", "Billable", "Note",
+}
+
+// exportRow is a row of an exported report: either a record or, when Summary is set,
+// the rounded total of a project.
+type exportRow struct {
+	ID       uuid.UUID
+	Project  string
+	Client   string
+	User     string
+	Task     string
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+	Billable bool
+	Note     string
+	Summary  bool
+}
+
+// exportReport returns the rows of a report, with a summary row after the records of
+// each project if summary is set.  Records are sorted by start time and durations are
+// rounded by the rounding policy of the project.
+func exportReport(dbRequest models.DatabaseReportRequest, projects []string, billing billing,
+	summary bool,
+) ([]exportRow, error) {
+	rows := []exportRow{}
+	for _, project := range projects {
+		dbRequest.Project = project
+		data, err := database.GetReportRecords(dbRequest)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) == 0 {
+			continue
+		}
+		slices.SortFunc(data, func(a, b models.Record) int { return a.Start.Compare(b.Start) })
+		client := billing.client(project).Name
+		totals := reportTotals{}
+		for _, d := range data {
+			totals.add(d, billing)
+			rows = append(rows, exportRow{
+				ID:       d.ID,
+				Project:  project,
+				Client:   client,
+				User:     d.User,
+				Task:     d.Task,
+				Start:    d.Start,
+				End:      d.End,
+				Duration: billing.rounding(project).Record(d.Duration()),
+				Billable: d.Billable,
+				Note:     d.Note,
+			})
+		}
+		if summary {
+			totals.round(billing.rounding(project))
+			rows = append(rows, exportRow{
+				Project:  project,
+				Client:   client,
+				Duration: totals.total,
+				Summary:  true,
+			})
+		}
+	}
+	return rows, nil
+}
+
+// loadExport returns the request and rows of a report to export.  An error response
+// is written if the report cannot be loaded.
+func loadExport(w http.ResponseWriter, r *http.Request, summary bool) (models.ReportRequest, []exportRow, bool) {
+	user := getRequestUser(r)
+	reportRequest, dbRequest, err := parseReportRequest(r, user)
+	if err != nil {
+		processError(w, reportErrorStatus(err), err.Error())
+		return reportRequest, nil, false
+	}
+	billing, err := loadBilling()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return reportRequest, nil, false
+	}
+	rows, err := exportReport(dbRequest, reportProjects(reportRequest, billing), billing, summary)
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return reportRequest, nil, false
+	}
+	return reportRequest, rows, true
+}
+
+// exportCSV downloads the records of a report as CSV.
+func exportCSV(w http.ResponseWriter, r *http.Request) {
+	reportRequest, rows, ok := loadExport(w, r, r.FormValue("summary") != "")
+	if !ok {
+		return
+	}
+	location := userLocation(getRequestUser(r).Username)
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", exportFilename(reportRequest, "csv"))
+	writer := csv.NewWriter(w)
+	records := [][]string{exportHeader}
+	for _, row := range rows {
+		records = append(records, row.csv(location))
+	}
+	if err := writer.WriteAll(records); err != nil {
+		slog.Error("write csv", "error", err)
+	}
+}
+
+// exportXLSX downloads a report as an XLSX workbook with a summary sheet and a sheet
+// for each project.
+func exportXLSX(w http.ResponseWriter, r *http.Request) {
+	reportRequest, rows, ok := loadExport(w, r, true)
+	if !ok {
+		return
+	}
+	location := userLocation(getRequestUser(r).Username)
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", exportFilename(reportRequest, "xlsx"))
+	if err := writeXLSX(w, reportSheets(rows, location)); err != nil {
+		slog.Error("write xlsx", "error", err)
+	}
+}
+
+// reportSheets returns the worksheets of a report: a summary of the rounded project
+// totals followed by the records of each project with their totals.
+func reportSheets(rows []exportRow, location *time.Location) []xlsxSheet {
+	summary := xlsxSheet{Name: "Summary", Rows: [][]xlsxCell{headerCells("Project", "Client", "Duration", "Hours")}}
+	sheets := []xlsxSheet{}
+	var total time.Duration
+	for start := 0; start < len(rows); {
+		end := start + slices.IndexFunc(rows[start:], func(row exportRow) bool { return row.Summary })
+		sheets = append(sheets, projectSheet(rows[start:end], rows[end], location))
+		n := len(summary.Rows)
+		summary.Rows = append(summary.Rows, []xlsxCell{
+			textCell(rows[end].Project, styleDefault),
+			textCell(rows[end].Client, styleDefault),
+			durationCell(rows[end].Duration),
+			formulaCell(cellName(2, n)+"*24", rows[end].Duration.Hours(), styleHours),
+		})
+		total += rows[end].Duration
+		start = end + 1
+	}
+	summary.Rows = append(summary.Rows, totalCells(2, len(summary.Rows), total))
+	return append([]xlsxSheet{summary}, sheets...)
+}
+
+// projectSheet returns the worksheet of the records of a project followed by their
+// total and, if the project rounds its total, the rounded total.
+func projectSheet(records []exportRow, rounded exportRow, location *time.Location) xlsxSheet {
+	sheet := xlsxSheet{
+		Name: rounded.Project,
+		Rows: [][]xlsxCell{headerCells("User", "Task", "Start", "End", "Duration", "Hours", "Billable", "Note")},
+	}
+	var total time.Duration
+	for _, record := range records {
+		n := len(sheet.Rows)
+		sheet.Rows = append(sheet.Rows, []xlsxCell{
+			textCell(record.User, styleDefault),
+			textCell(record.Task, styleDefault),
+			timeCell(record.Start.In(location)),
+			timeCell(record.End.In(location)),
+			durationCell(record.Duration),
+			formulaCell(cellName(4, n)+"*24", record.Duration.Hours(), styleHours),
+			textCell(strconv.FormatBool(record.Billable), styleDefault),
+			textCell(record.Note, styleDefault),
+		})
+		total += record.Duration
+	}
+	sheet.Rows = append(sheet.Rows, totalCells(4, len(sheet.Rows), total))
+	if rounded.Duration != total {
+		n := len(sheet.Rows)
+		sheet.Rows = append(sheet.Rows, []xlsxCell{
+			textCell("Rounded total", styleHeader), {}, {}, {},
+			durationCell(rounded.Duration),
+			formulaCell(cellName(4, n)+"*24", rounded.Duration.Hours(), styleHours),
+		})
+	}
+	return sheet
+}
+
+// headerCells returns a row of column titles.
+func headerCells(titles ...string) []xlsxCell {
+	cells := []xlsxCell{}
+	for _, title := range titles {
+		cells = append(cells, textCell(title, styleHeader))
+	}
+	return cells
+}
+
+// totalCells returns a row of formulas totalling the duration column and the hours
+// column after it, for the rows between the header and the given row.
+func totalCells(column, row int, total time.Duration) []xlsxCell {
+	cells := make([]xlsxCell, column, column+2)
+	cells[0] = textCell("Total", styleHeader)
+	sum := func(column int) string {
+		return fmt.Sprintf("SUM(%s:%s)", cellName(column, 1), cellName(column, row-1))
+	}
+	return append(cells,
+		formulaCell(sum(column), durationCell(total).Number, styleDuration),
+		formulaCell(sum(column+1), total.Hours(), styleHours),
+	)
+}
+
+// csv returns the fields of the row, with times in the given location.
+func (row exportRow) csv(location *time.Location) []string {
+	duration := []string{models.FmtClock(row.Duration), fmtDecimalHours(row.Duration)}
+	if row.Summary {
+		fields := append([]string{csvText(row.Project), csvText(row.Client), "Total", "", "", ""}, duration...)
+		return append(fields, "", "")
+	}
+	fields := []string{
+		csvText(row.Project), csvText(row.Client), csvText(row.User), csvText(row.Task),
+		row.Start.In(location).Format(time.DateTime),
+		row.End.In(location).Format(time.DateTime),
+	}
+	fields = append(fields, duration...)
+	return append(fields, strconv.FormatBool(row.Billable), csvText(row.Note))
+}
+
+// csvText returns user entered text for a CSV field.  Text that a spreadsheet would
+// take for a formula is prefixed with a quote so it is shown as entered.
+func csvText(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+// exportFilename returns a Content-Disposition header for an exported report.
+func exportFilename(request models.ReportRequest, extension string) string {
+	return fmt.Sprintf("attachment; filename=\"timetrace-%s-%s.%s\"", request.Start, request.End, extension)
+}
+
+// fmtDecimalHours formats a duration as hours with two decimal places, such as 1.50.
+func fmtDecimalHours(d time.Duration) string {
+	return strconv.FormatFloat(d.Hours(), 'f', 2, 64)
+}
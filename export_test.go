@@ -0,0 +1,174 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+func TestExport(t *testing.T) {
+	deleteAllUsers()
+	deleteAllRecords()
+	deleteAllProjects()
+	deleteAllClients()
+	user := models.User{Username: "test", Password: "testing", TimeZone: "America/New_York"}
+	should.BeNil(t, createTestUser(user))
+	cookie := testLogin(user)
+	should.NotBeNil(t, cookie)
+	should.BeNil(t, database.SaveProject(&models.Project{
+		Name:     "export",
+		Active:   true,
+		Rounding: models.Rounding{Mode: models.RoundUp, Minutes: 15, PerTotal: true},
+	}))
+	start := time.Date(2026, 10, 14, 13, 0, 0, 0, time.UTC)
+	for _, minutes := range []int{7, 20} {
+		should.BeNil(t, database.SaveRecord(&models.Record{
+			ID:       uuid.New(),
+			Project:  "export",
+			User:     "test",
+			Start:    start,
+			End:      start.Add(time.Duration(minutes) * time.Minute),
+			Note:     "notes, with a comma",
+			Billable: true,
+		}))
+		start = start.Add(time.Hour)
+	}
+	download := func(query string) (*httptest.ResponseRecorder, [][]string) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/reports/csv?"+query, nil)
+		r.AddCookie(cookie)
+		router.ServeHTTP(w, r)
+		rows, _ := csv.NewReader(w.Body).ReadAll()
+		return w, rows
+	}
+
+	t.Run("records", func(t *testing.T) {
+		w, rows := download("start=2026-10-14&end=2026-10-14")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.BeEqual(t, w.Header().Get("Content-Type"), "text/csv; charset=utf-8")
+		should.ContainSubstring(t, w.Header().Get("Content-Disposition"),
+			"timetrace-2026-10-14-2026-10-14.csv")
+		should.BeEqual(t, len(rows), 3)
+		should.BeEqual(t, rows[0], exportHeader)
+		should.BeEqual(t, rows[1], []string{
+			"export", "", "test", "", "2026-10-14 09:00:00", "2026-10-14 09:07:00",
+			"00:07", "0.12", "true", "notes, with a comma",
+		})
+	})
+	t.Run("summary", func(t *testing.T) {
+		_, rows := download("start=2026-10-14&end=2026-10-14&summary=true")
+		should.BeEqual(t, len(rows), 4)
+		should.BeEqual(t, rows[3], []string{"export", "", "Total", "", "", "", "00:30", "0.50", "", ""})
+	})
+	t.Run("roundingPerRecord", func(t *testing.T) {
+		project, err := database.GetProject("export")
+		should.BeNil(t, err)
+		project.Rounding.PerTotal = false
+		should.BeNil(t, database.SaveProject(&project))
+		_, rows := download("start=2026-10-14&end=2026-10-14&summary=true")
+		should.BeEqual(t, rows[1][6], "00:15")
+		should.BeEqual(t, rows[2][7], "0.50")
+		should.BeEqual(t, rows[3][6], "00:45")
+	})
+	t.Run("otherDay", func(t *testing.T) {
+		_, rows := download("start=2026-10-15&end=2026-10-15")
+		should.BeEqual(t, len(rows), 1)
+	})
+	t.Run("xlsx", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/reports/xlsx?start=2026-10-14&end=2026-10-14", nil)
+		r.AddCookie(cookie)
+		router.ServeHTTP(w, r)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Header().Get("Content-Disposition"), "timetrace-2026-10-14-2026-10-14.xlsx")
+		archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		should.BeNil(t, err)
+		should.BeEqual(t, len(archive.File), 7)
+	})
+	t.Run("ics", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/reports/ics?start=2026-10-14&end=2026-10-14", nil)
+		r.AddCookie(cookie)
+		router.ServeHTTP(w, r)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Header().Get("Content-Disposition"), "timetrace-2026-10-14-2026-10-14.ics")
+		should.BeEqual(t, strings.Count(w.Body.String(), "BEGIN:VEVENT"), 2)
+		should.ContainSubstring(t, w.Body.String(), "DTSTART:20261014T130000Z")
+	})
+	t.Run("icsOvernight", func(t *testing.T) {
+		location, err := time.LoadLocation(user.TimeZone)
+		should.BeNil(t, err)
+		start := time.Date(2026, 10, 16, 22, 0, 0, 0, location)
+		should.BeNil(t, database.SaveRecord(&models.Record{
+			ID:      uuid.New(),
+			Project: "export",
+			User:    "test",
+			Start:   start,
+			End:     start.Add(4 * time.Hour),
+		}))
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/reports/ics?start=2026-10-17&end=2026-10-17", nil)
+		r.AddCookie(cookie)
+		router.ServeHTTP(w, r)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "DTSTART:"+start.UTC().Format(icsTimeFormat))
+		should.ContainSubstring(t, w.Body.String(), "DTEND:"+start.Add(4*time.Hour).UTC().Format(icsTimeFormat))
+	})
+	t.Run("badDate", func(t *testing.T) {
+		w, _ := download("start=today")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+}
+
+func TestReportSheets(t *testing.T) {
+	start := time.Date(2026, 10, 14, 13, 0, 0, 0, time.UTC)
+	rows := []exportRow{
+		{Project: "first", User: "test", Start: start, End: start.Add(7 * time.Minute), Duration: 7 * time.Minute},
+		{Project: "first", User: "test", Start: start, End: start.Add(time.Hour), Duration: time.Hour},
+		{Project: "first", Duration: 75 * time.Minute, Summary: true},
+		{Project: "second", Client: "acme", Start: start, End: start.Add(time.Hour), Duration: time.Hour},
+		{Project: "second", Client: "acme", Duration: time.Hour, Summary: true},
+	}
+	sheets := reportSheets(rows, time.UTC)
+	should.BeEqual(t, len(sheets), 3)
+	summary := sheets[0]
+	should.BeEqual(t, len(summary.Rows), 4)
+	should.BeEqual(t, summary.Rows[1][0].Text, "first")
+	should.BeEqual(t, summary.Rows[2][1].Text, "acme")
+	should.BeEqual(t, summary.Rows[3][2].Formula, "SUM(C2:C3)")
+	should.BeEqual(t, summary.Rows[3][3].Number, 2.25)
+	first := sheets[1]
+	should.BeEqual(t, first.Name, "first")
+	// header, two records, total and rounded total
+	should.BeEqual(t, len(first.Rows), 5)
+	should.BeEqual(t, first.Rows[1][5].Formula, "E2*24")
+	should.BeEqual(t, first.Rows[3][4].Formula, "SUM(E2:E3)")
+	should.BeEqual(t, first.Rows[4][4], durationCell(75*time.Minute))
+	should.BeEqual(t, len(sheets[2].Rows), 3)
+}
+
+func TestExportRowCSV(t *testing.T) {
+	start := time.Date(2026, 10, 14, 13, 0, 0, 0, time.UTC)
+	row := exportRow{
+		Project: "web", User: "test", Task: "-deploy", Start: start, End: start.Add(time.Hour),
+		Duration: time.Hour, Note: `=HYPERLINK("http://example.com")`,
+	}
+	fields := row.csv(time.UTC)
+	should.BeEqual(t, fields[0], "web")
+	should.BeEqual(t, fields[3], "'-deploy")
+	should.BeEqual(t, fields[9], `'=HYPERLINK("http://example.com")`)
+	for _, text := range []string{"+1", "@SUM(A1)", "\tcmd"} {
+		should.BeEqual(t, csvText(text), "'"+text)
+	}
+	should.BeEqual(t, csvText("notes, with a comma"), "notes, with a comma")
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+)
+
+const (
+	// feedDays is the number of days of records in a calendar subscription.
+	feedDays = 90
+	// icsLineLength is the maximum length in octets of an iCalendar content line.
+	icsLineLength = 75
+	icsTimeFormat = "20060102T150405Z"
+)
+
+// exportICS downloads the records of a report as an iCalendar file.
+func exportICS(w http.ResponseWriter, r *http.Request) {
+	reportRequest, rows, ok := loadExport(w, r, false)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	if err := unclipRows(rows, now); err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", exportFilename(reportRequest, "ics"))
+	if err := writeICS(w, rows, now); err != nil {
+		slog.Error("write ics", "error", err)
+	}
+}
+
+// unclipRows restores the full times of the records of rows, which a report clips to
+// its days.  Calendar clients replace events by UID, so a truncated event would
+// overwrite the complete event of a subscription or an earlier export.
+func unclipRows(rows []exportRow, now time.Time) error {
+	for i, row := range rows {
+		record, err := database.GetRecord(row.ID)
+		if err != nil {
+			return err
+		}
+		if record.End.IsZero() {
+			record.End = now
+		}
+		rows[i].Start, rows[i].End = record.Start, record.End
+	}
+	return nil
+}
+
+// calendarFeed serves the recent records of the user owning the token in the path as
+// an iCalendar subscription.  No login is required as calendar clients cannot log in.
+func calendarFeed(w http.ResponseWriter, r *http.Request) {
+	user, err := feedUser(r.PathValue("token"))
+	if err != nil {
+		processError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	records, err := database.GetAllRecordsForUser(user.Username)
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	now := time.Now()
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if err := writeICS(w, feedRows(records, now.AddDate(0, 0, -feedDays), now), now); err != nil {
+		slog.Error("write feed", "error", err)
+	}
+}
+
+// getFeed displays the calendar subscription URL of the user.
+func getFeed(w http.ResponseWriter, r *http.Request) {
+	user, err := database.GetUser(getRequestUser(r).Username)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	renderFeed(w, r, user)
+}
+
+// newFeedToken creates a new calendar subscription token for the user, which replaces
+// any previous token.
+func newFeedToken(w http.ResponseWriter, r *http.Request) {
+	user, err := database.GetUser(getRequestUser(r).Username)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	user.FeedToken = rand.Text()
+	user.Updated = time.Now()
+	if err := database.SaveUser(&user); err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	slog.Info("calendar feed token created", "user", user.Username)
+	renderFeed(w, r, user)
+}
+
+func renderFeed(w http.ResponseWriter, r *http.Request, user models.User) {
+	feed := models.Feed{Days: feedDays}
+	if user.FeedToken != "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		feed.URL = fmt.Sprintf("%s://%s/feed/%s", scheme, r.Host, user.FeedToken)
+	}
+	render(w, "feed", feed)
+}
+
+// feedUser returns the user owning a calendar subscription token.
+func feedUser(token string) (models.User, error) {
+	users, err := database.GetAllUsers()
+	if err != nil {
+		return models.User{}, err
+	}
+	for _, user := range users {
+		if user.FeedToken != "" && subtle.ConstantTimeCompare([]byte(user.FeedToken), []byte(token)) == 1 {
+			return user, nil
+		}
+	}
+	return models.User{}, errors.New("no such feed")
+}
+
+// feedRows returns the records started between start and now sorted by start time.
+// Records still being tracked end now.
+func feedRows(records []models.Record, start, now time.Time) []exportRow {
+	rows := []exportRow{}
+	for _, record := range records {
+		if record.Start.Before(start) {
+			continue
+		}
+		if record.End.IsZero() {
+			record.End = now
+		}
+		rows = append(rows, recordRow(record))
+	}
+	slices.SortFunc(rows, func(a, b exportRow) int { return a.Start.Compare(b.Start) })
+	return rows
+}
+
+// writeICS writes the rows as an iCalendar file with an event for each record.  The
+// UID of an event is derived from the ID of the record so that calendar clients
+// update rather than duplicate events.
+func writeICS(w io.Writer, rows []exportRow, now time.Time) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//devilcove//timetrace//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:Timetrace",
+	}
+	for _, row := range rows {
+		if row.Summary {
+			continue
+		}
+		summary := row.Project
+		if row.Task != "" {
+			summary += ": " + row.Task
+		}
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+row.ID.String()+"@timetrace",
+			"DTSTAMP:"+now.UTC().Format(icsTimeFormat),
+			"DTSTART:"+row.Start.UTC().Format(icsTimeFormat),
+			"DTEND:"+row.End.UTC().Format(icsTimeFormat),
+			"SUMMARY:"+escapeICS(summary),
+		)
+		if row.Note != "" {
+			lines = append(lines, "DESCRIPTION:"+escapeICS(row.Note))
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+	for _, line := range lines {
+		if _, err := io.WriteString(w, foldICS(line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeICS escapes an iCalendar text value.
+func escapeICS(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+// foldICS returns a content line terminated by CRLF and folded so that no line is
+// longer than icsLineLength octets, without splitting UTF-8 characters.
+func foldICS(line string) string {
+	var b strings.Builder
+	limit := icsLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space
+		limit = icsLineLength - 1
+	}
+	b.WriteString(line + "\r\n")
+	return b.String()
+}
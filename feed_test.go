@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+func TestCalendarFeed(t *testing.T) {
+	deleteAllUsers()
+	deleteAllRecords()
+	user := models.User{Username: "test", Password: "testing"}
+	should.BeNil(t, createTestUser(user))
+	cookie := testLogin(user)
+	id := uuid.New()
+	should.BeNil(t, database.SaveRecord(&models.Record{
+		ID:      id,
+		Project: "feed",
+		User:    "test",
+		Start:   time.Now().Add(-2 * time.Hour),
+		End:     time.Now().Add(-time.Hour),
+		Note:    "planning; budget",
+	}))
+	should.BeNil(t, database.SaveRecord(&models.Record{
+		ID:      uuid.New(),
+		Project: "old",
+		User:    "test",
+		Start:   time.Now().AddDate(0, 0, -feedDays-1),
+		End:     time.Now().AddDate(0, 0, -feedDays-1).Add(time.Hour),
+	}))
+	request := func(cookie *http.Cookie, method, url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, url, nil)
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+		router.ServeHTTP(w, r)
+		return w
+	}
+	t.Run("noToken", func(t *testing.T) {
+		w := request(cookie, http.MethodGet, "/calendar/feed/")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "You do not have a subscription address yet")
+	})
+	var first string
+	t.Run("create", func(t *testing.T) {
+		w := request(cookie, http.MethodPost, "/calendar/feed/")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		saved, err := database.GetUser("test")
+		should.BeNil(t, err)
+		should.BeFalse(t, saved.FeedToken == "")
+		should.ContainSubstring(t, w.Body.String(), "http://example.com/feed/"+saved.FeedToken)
+		first = saved.FeedToken
+	})
+	t.Run("subscribe", func(t *testing.T) {
+		w := request(nil, http.MethodGet, "/feed/"+first)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.BeEqual(t, w.Header().Get("Content-Type"), "text/calendar; charset=utf-8")
+		body := w.Body.String()
+		should.ContainSubstring(t, body, "UID:"+id.String()+"@timetrace\r\n")
+		should.ContainSubstring(t, body, "SUMMARY:feed\r\n")
+		should.ContainSubstring(t, body, `DESCRIPTION:planning\; budget`)
+		should.BeFalse(t, strings.Contains(body, "SUMMARY:old"))
+	})
+	t.Run("replace", func(t *testing.T) {
+		w := request(cookie, http.MethodPost, "/calendar/feed/")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		w = request(nil, http.MethodGet, "/feed/"+first)
+		should.BeEqual(t, w.Code, http.StatusNotFound)
+	})
+	t.Run("badToken", func(t *testing.T) {
+		w := request(nil, http.MethodGet, "/feed/junk")
+		should.BeEqual(t, w.Code, http.StatusNotFound)
+	})
+}
+
+func TestWriteICS(t *testing.T) {
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	id := uuid.New()
+	rows := []exportRow{
+		{ID: id, Project: "web", Task: "design", Start: start, End: start.Add(time.Hour), Note: "a, b\nc"},
+		{Project: "web", Duration: time.Hour, Summary: true},
+	}
+	var buf bytes.Buffer
+	should.BeNil(t, writeICS(&buf, rows, start))
+	should.BeEqual(t, buf.String(), strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//devilcove//timetrace//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:Timetrace",
+		"BEGIN:VEVENT",
+		"UID:" + id.String() + "@timetrace",
+		"DTSTAMP:20261014T090000Z",
+		"DTSTART:20261014T090000Z",
+		"DTEND:20261014T100000Z",
+		"SUMMARY:web: design",
+		`DESCRIPTION:a\, b\nc`,
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n"))
+}
+
+func TestFoldICS(t *testing.T) {
+	should.BeEqual(t, foldICS("SUMMARY:short"), "SUMMARY:short\r\n")
+	long := "DESCRIPTION:" + strings.Repeat("é", 100)
+	folded := foldICS(long)
+	lines := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+	should.BeEqual(t, len(lines), 3)
+	for i, line := range lines {
+		should.BeTrue(t, len(line) <= icsLineLength)
+		if i > 0 {
+			should.BeTrue(t, strings.HasPrefix(line, " "))
+		}
+	}
+	unfolded, err := unfoldICS(strings.NewReader(folded))
+	should.BeNil(t, err)
+	should.BeEqual(t, unfolded, []string{long})
+}
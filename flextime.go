@@ -0,0 +1,196 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+)
+
+func getSchedule(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	name := r.PathValue("name")
+	if !editor.IsAdmin && name != editor.Username {
+		processError(w, http.StatusUnauthorized, "you are not authorized to edit this schedule")
+		return
+	}
+	user, err := database.GetUser(name)
+	if err != nil {
+		processError(w, http.StatusBadRequest, "user does not exist")
+		return
+	}
+	renderSchedule(w, user)
+}
+
+func saveSchedule(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	name := r.PathValue("name")
+	if !editor.IsAdmin && name != editor.Username {
+		processError(w, http.StatusUnauthorized, "you are not authorized to edit this schedule")
+		return
+	}
+	user, err := database.GetUser(name)
+	if err != nil {
+		processError(w, http.StatusBadRequest, "user does not exist")
+		return
+	}
+	schedule := models.Schedule{}
+	for _, day := range models.Weekdays() {
+		schedule.Hours[day], err = parseHours(r.FormValue(strings.ToLower(day.String())))
+		if err != nil || schedule.Hours[day] > 24*time.Hour {
+			processError(w, http.StatusBadRequest, "invalid hours for "+day.String())
+			return
+		}
+	}
+	holidays := r.FormValue("holidays")
+	if holidays != "" {
+		if _, err := database.GetHolidayCalendar(holidays); err != nil {
+			processError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	timeZone := r.FormValue("timezone")
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		processError(w, http.StatusBadRequest, "invalid time zone")
+		return
+	}
+	// flextime is counted from the start of a day in the user's new time zone
+	location := models.User{TimeZone: timeZone}.Location()
+	schedule.Start = startOfDay(time.Now().In(location))
+	if start := r.FormValue("start"); start != "" {
+		schedule.Start, err = time.ParseInLocation("2006-01-02", start, location)
+		if err != nil {
+			processError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	user.Schedule = schedule
+	user.Holidays = holidays
+	user.TimeZone = timeZone
+	user.Updated = time.Now()
+	if err := database.SaveUser(&user); err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	slog.Info("schedule updated", "user", user.Username)
+	renderSchedule(w, user)
+}
+
+func renderSchedule(w http.ResponseWriter, user models.User) {
+	editor := models.ScheduleEditor{
+		Username: user.Username,
+		Start:    time.Now().Format("2006-01-02"),
+		Holidays: user.Holidays,
+		TimeZone: user.TimeZone,
+	}
+	calendars, err := database.GetAllHolidayCalendars()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, calendar := range calendars {
+		editor.Calendars = append(editor.Calendars, calendar.Name)
+	}
+	if user.Schedule.IsSet() {
+		editor.Start = user.Schedule.Start.Format("2006-01-02")
+	}
+	for _, day := range models.Weekdays() {
+		editor.Days = append(editor.Days, models.ScheduleDay{
+			Day:   day.String(),
+			Field: strings.ToLower(day.String()),
+			Hours: models.FmtHours(user.Schedule.Hours[day]),
+		})
+	}
+	render(w, "schedule", editor)
+}
+
+// startOfDay returns midnight of the day of t in the location of t.
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// flexPeriod compares the time worked within [start, end), clipping records to it,
+// with the time expected by schedule.  No time is expected on days off or after
+// today, and records still being tracked are counted up to now.
+func flexPeriod(
+	schedule models.Schedule,
+	off models.DaysOff,
+	records []models.Record,
+	start, end, now time.Time,
+) models.FlexPeriod {
+	period := models.FlexPeriod{Start: start, End: end}
+	tomorrow := startOfDay(now).AddDate(0, 0, 1)
+	for day := start; day.Before(end) && day.Before(tomorrow); day = day.AddDate(0, 0, 1) {
+		if off.Reason(day) == "" {
+			period.Expected += schedule.Expected(day)
+		}
+	}
+	// no time is counted before the schedule started
+	from := start
+	if schedule.Start.After(from) {
+		from = schedule.Start
+	}
+	for _, record := range records {
+		if record.End.IsZero() {
+			record.End = now
+		}
+		if record.Clip(from, end) {
+			period.Worked += record.Duration()
+		}
+	}
+	return period
+}
+
+// flexBalance returns the running flextime balance of user up to the start of today
+// and the flextime of today.  Nil is returned if the user has no work schedule.
+func flexBalance(user models.User) (*models.FlexPeriod, *models.FlexPeriod) {
+	if !user.Schedule.IsSet() {
+		return nil, nil
+	}
+	records, err := database.GetAllRecordsForUser(user.Username)
+	if err != nil {
+		slog.Error("get records", "user", user.Username, "error", err)
+		return nil, nil
+	}
+	location := user.Location()
+	now := time.Now().In(location)
+	today := startOfDay(now)
+	start := startOfDay(user.Schedule.Start.In(location))
+	off := userDaysOff(user, start, today.AddDate(0, 0, 1))
+	balance := flexPeriod(user.Schedule, off, records, start, today, now)
+	current := flexPeriod(user.Schedule, off, records, today, today.AddDate(0, 0, 1), now)
+	return &balance, &current
+}
+
+// reportFlex returns the weekly flextime changes of user for the days from start to
+// end.  Nil is returned if the user has no work schedule.
+func reportFlex(username string, start, end time.Time) []models.FlexPeriod {
+	user, err := database.GetUser(username)
+	if err != nil || !user.Schedule.IsSet() {
+		return nil
+	}
+	records, err := database.GetAllRecordsForUser(username)
+	if err != nil {
+		slog.Error("get records", "user", username, "error", err)
+		return nil
+	}
+	location := user.Location()
+	now := time.Now().In(location)
+	start = startOfDay(start.In(location))
+	end = startOfDay(end.In(location)).AddDate(0, 0, 1)
+	off := userDaysOff(user, start, end)
+	periods := []models.FlexPeriod{}
+	for from := start; from.Before(end); {
+		to := models.PeriodEnd(models.PeriodWeek, from)
+		if to.After(end) {
+			to = end
+		}
+		periods = append(periods, flexPeriod(user.Schedule, off, records, from, to, now))
+		from = to
+	}
+	return periods
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+func TestSchedules(t *testing.T) {
+	deleteAllRecords()
+	deleteAllUsers()
+	createAdmin()
+	worker := models.User{Username: "worker", Password: "testing"}
+	should.BeNil(t, createTestUser(worker))
+	request := func(cookie *http.Cookie, method, url string, params ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, bodyParams(params...))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	t.Run("get", func(t *testing.T) {
+		w := request(testLogin(worker), http.MethodGet, "/users/schedule/worker")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), `name="monday"`)
+	})
+	t.Run("otherUser", func(t *testing.T) {
+		w := request(testLogin(worker), http.MethodGet, "/users/schedule/admin")
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+		w = request(testLogin(worker), http.MethodPost, "/users/schedule/admin", "monday", "8")
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		w := request(testLogin(worker), http.MethodPost, "/users/schedule/worker", "monday", "25")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "invalid hours for Monday")
+		w = request(testLogin(worker), http.MethodPost, "/users/schedule/worker", "start", "junk")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		w = request(testLogin(worker), http.MethodPost, "/users/schedule/worker", "timezone", "Mars/Olympus")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "invalid time zone")
+	})
+	t.Run("save", func(t *testing.T) {
+		start := time.Now().AddDate(0, 0, -7).Format("2006-01-02")
+		params := []string{"start", start, "timezone", "Europe/Berlin"}
+		for _, day := range []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"} {
+			params = append(params, day, "1")
+		}
+		w := request(adminLogin(), http.MethodPost, "/users/schedule/worker", params...)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		user, err := database.GetUser("worker")
+		should.BeNil(t, err)
+		should.BeEqual(t, user.Schedule.Hours[time.Sunday], time.Hour)
+		should.BeEqual(t, user.Schedule.Start.Format("2006-01-02"), start)
+		should.BeEqual(t, user.Location().String(), "Europe/Berlin")
+	})
+	t.Run("status", func(t *testing.T) {
+		should.BeNil(t, database.SaveRecord(&models.Record{
+			ID:      uuid.New(),
+			Project: "test",
+			User:    "worker",
+			Start:   startOfDay(time.Now()).AddDate(0, 0, -1).Add(9 * time.Hour),
+			End:     startOfDay(time.Now()).AddDate(0, 0, -1).Add(12 * time.Hour),
+		}))
+		w := request(testLogin(worker), http.MethodGet, "/status/")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "Flextime")
+		// seven scheduled hours, three worked
+		should.ContainSubstring(t, w.Body.String(), models.FmtBalance(-4*time.Hour))
+	})
+	t.Run("report", func(t *testing.T) {
+		w := request(testLogin(worker), http.MethodPost, "/reports/",
+			"start", time.Now().AddDate(0, 0, -7).Format("2006-01-02"),
+			"end", time.Now().Format("2006-01-02"))
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "Flextime")
+	})
+}
+
+func TestStartOfDay(t *testing.T) {
+	location, err := time.LoadLocation("Asia/Kolkata")
+	should.BeNil(t, err)
+	day := startOfDay(time.Date(2026, 10, 19, 23, 30, 0, 0, location))
+	should.BeEqual(t, day, time.Date(2026, 10, 19, 0, 0, 0, 0, location))
+}
+
+func TestFlexPeriod(t *testing.T) {
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	schedule := models.Schedule{Start: monday.AddDate(0, 0, 1)}
+	for _, day := range models.Weekdays()[:5] {
+		schedule.Hours[day] = 8 * time.Hour
+	}
+	record := func(day, hours int) models.Record {
+		start := monday.AddDate(0, 0, day).Add(9 * time.Hour)
+		return models.Record{Start: start, End: start.Add(time.Duration(hours) * time.Hour)}
+	}
+	records := []models.Record{record(0, 8), record(1, 9), record(2, 8), record(5, 2)}
+	// Thursday morning; Monday precedes the schedule and Friday is in the future
+	now := monday.AddDate(0, 0, 3).Add(8 * time.Hour)
+	period := flexPeriod(schedule, models.DaysOff{}, records[:3], monday, monday.AddDate(0, 0, 7), now)
+	should.BeEqual(t, period.Expected, 24*time.Hour)
+	should.BeEqual(t, period.Worked, 17*time.Hour)
+	should.BeEqual(t, period.Balance(), -7*time.Hour)
+	later := monday.AddDate(0, 1, 0)
+	period = flexPeriod(schedule, models.DaysOff{}, records, monday, monday.AddDate(0, 0, 7), later)
+	should.BeEqual(t, period.Expected, 32*time.Hour)
+	should.BeEqual(t, period.Worked, 19*time.Hour)
+	off := models.DaysOff{monday.AddDate(0, 0, 4).Format("2006-01-02"): "vacation"}
+	period = flexPeriod(schedule, off, records, monday, monday.AddDate(0, 0, 7), later)
+	should.BeEqual(t, period.Expected, 24*time.Hour)
+	// only the time within the period counts of a record spanning midnight
+	wednesday := monday.AddDate(0, 0, 2)
+	overnight := []models.Record{{Start: wednesday.Add(-2 * time.Hour), End: wednesday.Add(3 * time.Hour)}}
+	period = flexPeriod(schedule, models.DaysOff{}, overnight, wednesday, wednesday.AddDate(0, 0, 1), later)
+	should.BeEqual(t, period.Worked, 3*time.Hour)
+}
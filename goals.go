@@ -0,0 +1,212 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+)
+
+func getGoals(w http.ResponseWriter, r *http.Request) {
+	user, err := database.GetUser(getRequestUser(r).Username)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	week := time.Now().In(user.Location())
+	if value := r.FormValue("week"); value != "" {
+		week, err = time.ParseInLocation("2006-01-02", value, user.Location())
+		if err != nil {
+			processError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	renderGoals(w, user, week)
+}
+
+func saveGoal(w http.ResponseWriter, r *http.Request) {
+	user, err := database.GetUser(getRequestUser(r).Username)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	goal := models.Goal{Project: r.FormValue("project")}
+	if goal.Project != "" {
+		project, err := database.GetProject(goal.Project)
+		if err != nil {
+			processError(w, http.StatusBadRequest, "project does not exist")
+			return
+		}
+		if !canAccessProject(user, project) {
+			processError(w, http.StatusUnauthorized, "you are not a member of this project")
+			return
+		}
+	}
+	goal.Daily, err = parseHours(r.FormValue("daily"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, "invalid daily goal")
+		return
+	}
+	goal.Weekly, err = parseHours(r.FormValue("weekly"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, "invalid weekly goal")
+		return
+	}
+	user.Goals = slices.DeleteFunc(user.Goals, func(g models.Goal) bool { return g.Project == goal.Project })
+	if goal.Daily > 0 || goal.Weekly > 0 {
+		user.Goals = append(user.Goals, goal)
+		slices.SortFunc(user.Goals, func(a, b models.Goal) int {
+			return strings.Compare(a.Project, b.Project)
+		})
+	}
+	if err := database.SaveUser(&user); err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	slog.Info("goal saved", "user", user.Username, "project", goal.Project)
+	renderGoals(w, user, time.Now())
+}
+
+func deleteGoal(w http.ResponseWriter, r *http.Request) {
+	user, err := database.GetUser(getRequestUser(r).Username)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	project := r.FormValue("project")
+	user.Goals = slices.DeleteFunc(user.Goals, func(g models.Goal) bool { return g.Project == project })
+	if err := database.SaveUser(&user); err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	slog.Info("goal deleted", "user", user.Username, "project", project)
+	renderGoals(w, user, time.Now())
+}
+
+func renderGoals(w http.ResponseWriter, user models.User, week time.Time) {
+	records, err := database.GetAllRecordsForUser(user.Username)
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	week = week.In(user.Location())
+	start := models.PeriodStart(models.PeriodWeek, week)
+	off := userDaysOff(user, start, start.AddDate(0, 0, 7))
+	summary := weeklySummary(user.Goals, records, off, week, time.Now())
+	projects, err := database.GetAllProjects()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	sortProjects(projects)
+	for _, project := range projects {
+		if project.Active && canAccessProject(user, project) {
+			summary.Projects = append(summary.Projects, project.Name)
+		}
+	}
+	render(w, "goals", summary)
+}
+
+// currentGoals returns the progress of user toward their goals for today and this week.
+func currentGoals(user models.User) []models.GoalProgress {
+	if len(user.Goals) == 0 {
+		return nil
+	}
+	records, err := database.GetAllRecordsForUser(user.Username)
+	if err != nil {
+		slog.Error("get records", "user", user.Username, "error", err)
+		return nil
+	}
+	now := time.Now().In(user.Location())
+	today := startOfDay(now)
+	week := models.PeriodStart(models.PeriodWeek, today)
+	progress := []models.GoalProgress{}
+	if userDaysOff(user, today, today.AddDate(0, 0, 1)).Reason(today) == "" {
+		progress = goalProgress(user.Goals, records, models.PeriodDay, today, today.AddDate(0, 0, 1), now)
+	}
+	return append(progress,
+		goalProgress(user.Goals, records, models.PeriodWeek, week, week.AddDate(0, 0, 7), now)...)
+}
+
+// goalProgress returns the progress toward the daily or weekly targets of goals from
+// the time of records within [start, end).  Records still being tracked are counted
+// up to now.
+func goalProgress(
+	goals []models.Goal,
+	records []models.Record,
+	period string,
+	start, end, now time.Time,
+) []models.GoalProgress {
+	progress := []models.GoalProgress{}
+	for _, goal := range goals {
+		target := goal.Weekly
+		if period == models.PeriodDay {
+			target = goal.Daily
+		}
+		if target <= 0 {
+			continue
+		}
+		current := models.GoalProgress{
+			Project: goal.Project,
+			Period:  period,
+			Target:  target,
+			Open:    now.Before(end),
+		}
+		for _, record := range records {
+			if goal.Project != "" && record.Project != goal.Project {
+				continue
+			}
+			if record.End.IsZero() {
+				record.End = now
+			}
+			if record.Clip(start, end) {
+				current.Done += record.Duration()
+			}
+		}
+		progress = append(progress, current)
+	}
+	return progress
+}
+
+// weeklySummary returns the progress toward goals on each day, up to now, of the week
+// containing week and toward the weekly goals.  Daily goals do not apply on days off.
+func weeklySummary(
+	goals []models.Goal,
+	records []models.Record,
+	off models.DaysOff,
+	week, now time.Time,
+) models.GoalSummary {
+	start := models.PeriodStart(models.PeriodWeek, week)
+	end := start.AddDate(0, 0, 7)
+	summary := models.GoalSummary{
+		Week:     start,
+		Previous: start.AddDate(0, 0, -7).Format("2006-01-02"),
+		Next:     end.Format("2006-01-02"),
+		Goals:    goals,
+	}
+	tally := func(progress []models.GoalProgress) {
+		for _, p := range progress {
+			if p.Met() {
+				summary.Met++
+			} else if p.Missed() {
+				summary.Missed++
+			}
+		}
+	}
+	for day := start; day.Before(end) && !day.After(now); day = day.AddDate(0, 0, 1) {
+		if reason := off.Reason(day); reason != "" {
+			summary.Days = append(summary.Days, models.GoalDay{Date: day, Off: reason})
+			continue
+		}
+		daily := goalProgress(goals, records, models.PeriodDay, day, day.AddDate(0, 0, 1), now)
+		summary.Days = append(summary.Days, models.GoalDay{Date: day, Goals: daily})
+		tally(daily)
+	}
+	summary.Weekly = goalProgress(goals, records, models.PeriodWeek, start, end, now)
+	tally(summary.Weekly)
+	return summary
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+func TestGoals(t *testing.T) {
+	deleteAllRecords()
+	deleteAllProjects()
+	createTestProjects()
+	deleteAllUsers()
+	createAdmin()
+	request := func(method, url string, params ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, bodyParams(params...))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(adminLogin())
+		router.ServeHTTP(w, req)
+		return w
+	}
+	t.Run("get", func(t *testing.T) {
+		w := request(http.MethodGet, "/goals/")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "Goals")
+	})
+	t.Run("badWeek", func(t *testing.T) {
+		w := request(http.MethodGet, "/goals/?week=junk")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		w := request(http.MethodPost, "/goals/", "daily", "eight")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "invalid daily goal")
+		w = request(http.MethodPost, "/goals/", "weekly", "-4")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "invalid weekly goal")
+		w = request(http.MethodPost, "/goals/", "project", "junk", "daily", "1")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+	t.Run("save", func(t *testing.T) {
+		w := request(http.MethodPost, "/goals/", "daily", "8", "weekly", "40")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		w = request(http.MethodPost, "/goals/", "project", "test", "daily", "1")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		w = request(http.MethodPost, "/goals/", "project", "test", "daily", "0.5")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		user, err := database.GetUser("admin")
+		should.BeNil(t, err)
+		should.BeEqual(t, user.Goals, []models.Goal{
+			{Daily: 8 * time.Hour, Weekly: 40 * time.Hour},
+			{Project: "test", Daily: 30 * time.Minute},
+		})
+	})
+	t.Run("status", func(t *testing.T) {
+		should.BeNil(t, database.SaveRecord(&models.Record{
+			ID:      uuid.New(),
+			Project: "test",
+			User:    "admin",
+			Start:   time.Now().Add(-time.Hour),
+			End:     time.Now(),
+		}))
+		w := request(http.MethodGet, "/status/")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "All projects today")
+		should.ContainSubstring(t, w.Body.String(), "All projects this week")
+		should.ContainSubstring(t, w.Body.String(), "test today")
+		should.ContainSubstring(t, w.Body.String(), "goal met")
+	})
+	t.Run("delete", func(t *testing.T) {
+		w := request(http.MethodDelete, "/goals/?project=test")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		w = request(http.MethodPost, "/goals/", "weekly", "0")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		user, err := database.GetUser("admin")
+		should.BeNil(t, err)
+		should.BeEmpty(t, user.Goals)
+	})
+}
+
+func TestWeeklySummary(t *testing.T) {
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	record := func(day, hours int) models.Record {
+		start := monday.AddDate(0, 0, day).Add(9 * time.Hour)
+		return models.Record{
+			Project: "test",
+			Start:   start,
+			End:     start.Add(time.Duration(hours) * time.Hour),
+		}
+	}
+	records := []models.Record{record(0, 8), record(1, 6), record(2, 8), record(7, 8)}
+	goals := []models.Goal{{Daily: 7 * time.Hour, Weekly: 20 * time.Hour}}
+	// Wednesday afternoon
+	now := monday.AddDate(0, 0, 2).Add(15 * time.Hour)
+	summary := weeklySummary(goals, records, models.DaysOff{}, now, now)
+	should.BeEqual(t, summary.Week, monday)
+	should.BeEqual(t, summary.Previous, "2026-10-05")
+	should.BeEqual(t, summary.Next, "2026-10-19")
+	should.BeEqual(t, len(summary.Days), 3)
+	should.BeTrue(t, summary.Days[0].Goals[0].Met())
+	should.BeTrue(t, summary.Days[1].Goals[0].Missed())
+	should.BeTrue(t, summary.Days[2].Goals[0].Met())
+	should.BeEqual(t, summary.Weekly[0].Done, 22*time.Hour)
+	should.BeTrue(t, summary.Weekly[0].Open)
+	should.BeEqual(t, summary.Met, 3)
+	should.BeEqual(t, summary.Missed, 1)
+	// the following week is complete when viewed later
+	later := monday.AddDate(0, 1, 0)
+	summary = weeklySummary(goals, records, models.DaysOff{}, monday.AddDate(0, 0, 7), later)
+	should.BeEqual(t, len(summary.Days), 7)
+	should.BeFalse(t, summary.Weekly[0].Open)
+	should.BeEqual(t, summary.Met, 1)
+	should.BeEqual(t, summary.Missed, 7)
+	// a record spanning midnight counts on both days
+	overnight := []models.Record{{Start: monday.Add(20 * time.Hour), End: monday.Add(32 * time.Hour)}}
+	progress := goalProgress(goals, overnight, models.PeriodDay, monday.AddDate(0, 0, 1), monday.AddDate(0, 0, 2), later)
+	should.BeEqual(t, progress[0].Done, 8*time.Hour)
+}
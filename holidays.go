@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+)
+
+// maxImportSize is the largest file accepted for import.
+const maxImportSize = 1 << 20
+
+func getHolidays(w http.ResponseWriter, _ *http.Request) {
+	renderHolidays(w)
+}
+
+func addHoliday(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to edit holidays")
+		return
+	}
+	date, err := time.ParseInLocation("2006-01-02", r.FormValue("date"), time.Local)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	holiday := models.Holiday{Date: date, Name: strings.TrimSpace(r.FormValue("name"))}
+	if holiday.Name == "" {
+		processError(w, http.StatusBadRequest, "holiday name cannot be blank")
+		return
+	}
+	if err := saveHolidays(r.FormValue("calendar"), []models.Holiday{holiday}); err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	renderHolidays(w)
+}
+
+func importHolidays(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to edit holidays")
+		return
+	}
+	if err := r.ParseMultipartForm(maxImportSize); err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+	holidays, err := parseICS(file)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := saveHolidays(r.FormValue("calendar"), holidays); err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	renderHolidays(w)
+}
+
+func deleteHolidayCalendar(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to edit holidays")
+		return
+	}
+	name := r.PathValue("name")
+	if _, err := database.GetHolidayCalendar(name); err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	users, err := database.GetAllUsers()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, user := range users {
+		if user.Holidays == name {
+			processError(w, http.StatusBadRequest, "holiday calendar is used by "+user.Username)
+			return
+		}
+	}
+	if err := database.DeleteHolidayCalendar(name); err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	slog.Info("deleted", "holidays", name)
+	renderHolidays(w)
+}
+
+func renderHolidays(w http.ResponseWriter) {
+	calendars, err := database.GetAllHolidayCalendars()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	slices.SortFunc(calendars, func(a, b models.HolidayCalendar) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	render(w, "holidays", calendars)
+}
+
+// saveHolidays adds holidays to the named calendar, creating the calendar if it does
+// not exist.  An existing holiday on the same day is replaced.
+func saveHolidays(name string, holidays []models.Holiday) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("calendar name cannot be blank")
+	}
+	calendar, err := database.GetHolidayCalendar(name)
+	if err != nil {
+		calendar = models.HolidayCalendar{Name: name}
+	}
+	for _, holiday := range holidays {
+		calendar.Holidays = slices.DeleteFunc(calendar.Holidays, func(h models.Holiday) bool {
+			return h.Date.Equal(holiday.Date)
+		})
+		calendar.Holidays = append(calendar.Holidays, holiday)
+	}
+	slices.SortFunc(calendar.Holidays, func(a, b models.Holiday) int {
+		return a.Date.Compare(b.Date)
+	})
+	calendar.Updated = time.Now()
+	if err := database.SaveHolidayCalendar(&calendar); err != nil {
+		return err
+	}
+	slog.Info("holidays saved", "calendar", name, "holidays", len(holidays))
+	return nil
+}
+
+// parseICS returns the events of an iCalendar file as holidays.  An event spanning
+// several days becomes a holiday on each day.
+func parseICS(r io.Reader) ([]models.Holiday, error) {
+	holidays := []models.Holiday{}
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return holidays, err
+	}
+	var start, end time.Time
+	var name string
+	inEvent := false
+	for _, line := range lines {
+		property, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		property, _, _ = strings.Cut(strings.ToUpper(property), ";")
+		switch {
+		case property == "BEGIN" && value == "VEVENT":
+			inEvent = true
+			start, end, name = time.Time{}, time.Time{}, ""
+		case !inEvent:
+		case property == "DTSTART":
+			start, err = parseICSDate(value)
+		case property == "DTEND":
+			end, err = parseICSDate(value)
+		case property == "SUMMARY":
+			name = unescapeICS(value)
+		case property == "END" && value == "VEVENT":
+			inEvent = false
+			if start.IsZero() {
+				return holidays, errors.New("event without start date")
+			}
+			if !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			if end.After(start.AddDate(0, 0, maxAbsenceDays)) {
+				return holidays, errors.New("event spans more than a year")
+			}
+			for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+				holidays = append(holidays, models.Holiday{Date: day, Name: name})
+			}
+		}
+		if err != nil {
+			return holidays, err
+		}
+	}
+	if len(holidays) == 0 {
+		return holidays, errors.New("no events found")
+	}
+	return holidays, nil
+}
+
+// unfoldICS returns the lines of an iCalendar file with folded lines joined.
+func unfoldICS(r io.Reader) ([]string, error) {
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseICSDate returns the day of an iCalendar DATE or DATE-TIME value.
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, errors.New("invalid date " + value)
+	}
+	return time.ParseInLocation("20060102", value[:8], time.Local)
+}
+
+// unescapeICS removes the escaping from an iCalendar text value.
+func unescapeICS(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+func getInvoices(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to view invoices")
+		return
+	}
+	renderInvoices(w)
+}
+
+func createInvoice(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to create invoices")
+		return
+	}
+	_ = r.ParseForm()
+	invoice := models.Invoice{
+		ID:      uuid.New(),
+		Client:  strings.TrimSpace(r.FormValue("client")),
+		Created: time.Now(),
+	}
+	if invoice.Client == "" {
+		processError(w, http.StatusBadRequest, "client cannot be blank")
+		return
+	}
+	location := userLocation(editor.Username)
+	var err error
+	invoice.Start, err = time.ParseInLocation("2006-01-02", r.FormValue("start"), location)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	invoice.End, err = time.ParseInLocation("2006-01-02", r.FormValue("end"), location)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	billing, err := loadBilling()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	projects := r.Form["project"]
+	if client, ok := billing.clients[invoice.Client]; ok {
+		invoice.Currency = client.Currency
+		for _, project := range projects {
+			if billing.projects[project].Client != client.Name {
+				processError(w, http.StatusBadRequest, "project "+project+" does not belong to the client")
+				return
+			}
+		}
+		if len(projects) == 0 {
+			for _, project := range billing.projects {
+				if project.Client == client.Name {
+					projects = append(projects, project.Name)
+				}
+			}
+			if len(projects) == 0 {
+				processError(w, http.StatusBadRequest, "client has no projects")
+				return
+			}
+		}
+	} else if len(projects) == 0 {
+		// without projects every billable record would be invoiced to the client
+		processError(w, http.StatusBadRequest, "select the projects to invoice to an unregistered client")
+		return
+	}
+	records, err := invoiceableRecords(invoice.Start, invoice.End, projects)
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(records) == 0 {
+		processError(w, http.StatusBadRequest, "no billable records to invoice")
+		return
+	}
+	buildInvoice(&invoice, records, billing, r.FormValue("group") == "tag")
+	if err := database.SaveInvoice(&invoice); err != nil {
+		if errors.Is(err, database.ErrRecordInvoiced) {
+			// another invoice took some of the records since they were read
+			processError(w, http.StatusBadRequest, "records were invoiced in the meantime, please try again")
+			return
+		}
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	slog.Info("invoice created", "invoice", invoice.Reference(), "client", invoice.Client)
+	renderInvoices(w)
+}
+
+func getInvoice(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to view invoices")
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	invoice, err := database.GetInvoice(id)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	render(w, "invoice", invoice)
+}
+
+func voidInvoice(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to void invoices")
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	invoice, err := database.GetInvoice(id)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if invoice.Voided {
+		processError(w, http.StatusBadRequest, "invoice is already voided")
+		return
+	}
+	invoice.Voided = true
+	if err := database.SaveInvoice(&invoice); err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	slog.Info("invoice voided", "invoice", invoice.Reference())
+	renderInvoices(w)
+}
+
+func renderInvoices(w http.ResponseWriter) {
+	invoices, err := database.GetAllInvoices()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	slices.SortFunc(invoices, func(a, b models.Invoice) int {
+		return cmp.Compare(b.Number, a.Number)
+	})
+	page := models.InvoicePage{
+		Invoices:    invoices,
+		DefaultDate: time.Now().Local().Format("2006-01-02"),
+	}
+	projects, err := database.GetAllProjects()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, project := range projects {
+		page.Projects = append(page.Projects, project.Name)
+	}
+	clients, err := database.GetAllClients()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, client := range clients {
+		page.Clients = append(page.Clients, client.Name)
+	}
+	render(w, "invoices", page)
+}
+
+// invoiceableRecords returns the completed, billable, not yet invoiced records of all
+// users that started within the given days.  If projects is not empty, only records
+// of the listed projects are returned.
+func invoiceableRecords(start, end time.Time, projects []string) ([]models.Record, error) {
+	invoiceable := []models.Record{}
+	records, err := database.GetAllRecords()
+	if err != nil {
+		return invoiceable, err
+	}
+	end = end.AddDate(0, 0, 1)
+	for _, record := range records {
+		if !record.Billable || record.Invoiced() || record.End.IsZero() {
+			continue
+		}
+		if record.Start.Before(start) || !record.Start.Before(end) {
+			continue
+		}
+		if len(projects) > 0 && !slices.Contains(projects, record.Project) {
+			continue
+		}
+		invoiceable = append(invoiceable, record)
+	}
+	return invoiceable, nil
+}
+
+// buildInvoice adds line items for records to invoice.  Records are grouped by project
+// and, if byTag is set, by their tags within each project.  Durations are rounded
+// according to the rounding policy of each project.
+func buildInvoice(
+	invoice *models.Invoice,
+	records []models.Record,
+	billing billing,
+	byTag bool,
+) {
+	for _, record := range records {
+		tag := ""
+		if byTag {
+			tag = strings.Join(record.Tags, ", ")
+			if tag == "" {
+				tag = untagged
+			}
+		}
+		index := slices.IndexFunc(invoice.Lines, func(line models.InvoiceLine) bool {
+			return line.Project == record.Project && line.Tag == tag
+		})
+		if index < 0 {
+			invoice.Lines = append(invoice.Lines, models.InvoiceLine{
+				Project: record.Project,
+				Tag:     tag,
+			})
+			index = len(invoice.Lines) - 1
+		}
+		d := billing.rounding(record.Project).Record(record.Duration())
+		invoice.Lines[index].Duration += d
+		invoice.Lines[index].Amount += models.Amount(d, billing.rate(record))
+		invoice.Records = append(invoice.Records, record.ID)
+	}
+	for i, line := range invoice.Lines {
+		totals := reportTotals{billable: line.Duration}
+		totals.addAmount(invoice.Currency, line.Amount)
+		totals.round(billing.rounding(line.Project))
+		invoice.Lines[i].Duration = totals.billable
+		invoice.Lines[i].Amount = totals.amounts[invoice.Currency]
+		invoice.Total += totals.amounts[invoice.Currency]
+	}
+	slices.SortFunc(invoice.Lines, func(a, b models.InvoiceLine) int {
+		if c := strings.Compare(a.Project, b.Project); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Tag, b.Tag)
+	})
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+func TestInvoices(t *testing.T) {
+	deleteAllRecords()
+	deleteAllProjects()
+	deleteAllUsers()
+	createAdmin()
+	should.BeNil(t, createTestUser(models.User{Username: "test", Password: "testing"}))
+	_ = database.SaveProject(&models.Project{
+		ID:       uuid.New(),
+		Name:     "client",
+		Active:   true,
+		Billable: true,
+		Rates:    []models.Rate{{Amount: 10000}},
+	})
+	billable := models.Record{
+		ID:       uuid.New(),
+		Project:  "client",
+		User:     "test",
+		Start:    time.Now().Add(time.Hour * -3),
+		End:      time.Now().Add(time.Hour * -2),
+		Tags:     []string{"dev"},
+		Billable: true,
+	}
+	_ = database.SaveRecord(&billable)
+	_ = database.SaveRecord(&models.Record{
+		ID:      uuid.New(),
+		Project: "client",
+		User:    "test",
+		Start:   time.Now().Add(time.Hour * -5),
+		End:     time.Now().Add(time.Hour * -4),
+	})
+	today := time.Now().Format(time.DateOnly)
+	request := func(cookie *http.Cookie, method, url string, params ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, bodyParams(params...))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	t.Run("notAdmin", func(t *testing.T) {
+		cookie := testLogin(models.User{Username: "test", Password: "testing"})
+		w := request(cookie, http.MethodGet, "/invoices/")
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+	})
+	t.Run("list", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodGet, "/invoices/")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "New Invoice")
+	})
+	t.Run("noClient", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodPost, "/invoices/", "start", today, "end", today)
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+	t.Run("unknownClient", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodPost, "/invoices/",
+			"client", "Wile E. Coyote", "start", today, "end", today)
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "select the projects")
+		record, err := database.GetRecord(billable.ID)
+		should.BeNil(t, err)
+		should.BeFalse(t, record.Invoiced())
+	})
+	t.Run("otherClientProject", func(t *testing.T) {
+		should.BeNil(t, database.SaveClient(&models.Client{Name: "Globex", Currency: "USD"}))
+		defer deleteAllClients()
+		w := request(adminLogin(), http.MethodPost, "/invoices/",
+			"client", "Globex", "project", "client", "start", today, "end", today)
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "does not belong to the client")
+		record, err := database.GetRecord(billable.ID)
+		should.BeNil(t, err)
+		should.BeFalse(t, record.Invoiced())
+	})
+	t.Run("create", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodPost, "/invoices/",
+			"client", "ACME", "project", "client", "start", today, "end", today, "group", "tag")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "ACME")
+		record, err := database.GetRecord(billable.ID)
+		should.BeNil(t, err)
+		should.BeTrue(t, record.Invoiced())
+		invoice, err := database.GetInvoice(record.Invoice)
+		should.BeNil(t, err)
+		should.BeEqual(t, len(invoice.Lines), 1)
+		should.BeEqual(t, invoice.Lines[0].Tag, "dev")
+		should.BeEqual(t, invoice.Total, int64(10000))
+	})
+	t.Run("nothingToInvoice", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodPost, "/invoices/",
+			"client", "ACME", "project", "client", "start", today, "end", today)
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "no billable records")
+	})
+	t.Run("locked", func(t *testing.T) {
+		start := billable.Start.Add(-time.Minute)
+		w := request(adminLogin(), http.MethodPost, "/records/"+billable.ID.String(),
+			"Start", start.Format(time.DateOnly),
+			"StartTime", formatTimeOnly(start),
+			"End", billable.End.Format(time.DateOnly),
+			"EndTime", formatTimeOnly(billable.End),
+		)
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "record has been invoiced")
+		should.BeEqual(t, database.DeleteRecord(billable.ID), database.ErrRecordInvoiced)
+	})
+	t.Run("printable", func(t *testing.T) {
+		record, err := database.GetRecord(billable.ID)
+		should.BeNil(t, err)
+		w := request(adminLogin(), http.MethodGet, "/invoices/"+record.Invoice.String())
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "Bill To: ACME")
+		should.ContainSubstring(t, w.Body.String(), "100.00")
+	})
+	t.Run("void", func(t *testing.T) {
+		record, err := database.GetRecord(billable.ID)
+		should.BeNil(t, err)
+		w := request(adminLogin(), http.MethodPost, "/invoices/void/"+record.Invoice.String())
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "voided")
+		voided := record.Invoice
+		record, err = database.GetRecord(billable.ID)
+		should.BeNil(t, err)
+		should.BeFalse(t, record.Invoiced())
+		w = request(adminLogin(), http.MethodPost, "/invoices/void/"+voided.String())
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "already voided")
+	})
+	t.Run("badID", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodGet, "/invoices/junk")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		w = request(adminLogin(), http.MethodPost, "/invoices/void/"+uuid.New().String())
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+	createTestProjects()
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+)
+
+// canAccessProject reports whether user may see and track time on project.
+func canAccessProject(user models.User, project models.Project) bool {
+	return user.IsAdmin || project.IsMember(user.Username)
+}
+
+// canManageProject reports whether user may manage the membership of project.
+func canManageProject(user models.User, project models.Project) bool {
+	return user.IsAdmin || project.IsOwner(user.Username)
+}
+
+func getMembers(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	project, err := database.GetProject(r.PathValue("name"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, "error reading project "+err.Error())
+		return
+	}
+	if !canManageProject(editor, project) {
+		processError(w, http.StatusUnauthorized, "you are not authorized to manage project members")
+		return
+	}
+	renderMembers(w, project)
+}
+
+func addMember(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	project, err := database.GetProject(r.PathValue("name"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, "error reading project "+err.Error())
+		return
+	}
+	if !canManageProject(editor, project) {
+		processError(w, http.StatusUnauthorized, "you are not authorized to manage project members")
+		return
+	}
+	member := models.Member{
+		User: r.FormValue("user"),
+		Role: r.FormValue("role"),
+	}
+	if !models.ValidRole(member.Role) {
+		processError(w, http.StatusBadRequest, "invalid role")
+		return
+	}
+	if _, err := database.GetUser(member.User); err != nil {
+		processError(w, http.StatusBadRequest, "user does not exist")
+		return
+	}
+	if project.IsOwner(member.User) && member.Role != models.RoleOwner && project.Owners() == 1 {
+		processError(w, http.StatusBadRequest, "project must have an owner")
+		return
+	}
+	project.Members = slices.DeleteFunc(project.Members, func(m models.Member) bool {
+		return m.User == member.User
+	})
+	project.Members = append(project.Members, member)
+	slices.SortFunc(project.Members, func(a, b models.Member) int {
+		return strings.Compare(a.User, b.User)
+	})
+	project.Updated = time.Now()
+	if err := database.SaveProject(&project); err != nil {
+		processError(w, http.StatusInternalServerError, "error saving project "+err.Error())
+		return
+	}
+	slog.Info("member added", "project", project.Name, "user", member.User, "role", member.Role)
+	renderMembers(w, project)
+}
+
+func removeMember(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	project, err := database.GetProject(r.PathValue("name"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, "error reading project "+err.Error())
+		return
+	}
+	if !canManageProject(editor, project) {
+		processError(w, http.StatusUnauthorized, "you are not authorized to manage project members")
+		return
+	}
+	user := r.PathValue("user")
+	index := slices.IndexFunc(project.Members, func(m models.Member) bool { return m.User == user })
+	if index < 0 {
+		processError(w, http.StatusBadRequest, "no such member")
+		return
+	}
+	if project.IsOwner(user) && project.Owners() == 1 {
+		processError(w, http.StatusBadRequest, "project must have an owner")
+		return
+	}
+	project.Members = slices.Delete(project.Members, index, index+1)
+	project.Updated = time.Now()
+	if err := database.SaveProject(&project); err != nil {
+		processError(w, http.StatusInternalServerError, "error saving project "+err.Error())
+		return
+	}
+	slog.Info("member removed", "project", project.Name, "user", user)
+	renderMembers(w, project)
+}
+
+func renderMembers(w http.ResponseWriter, project models.Project) {
+	editor := models.ProjectEditor{Project: project}
+	users, err := database.GetAllUsers()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, user := range users {
+		editor.Users = append(editor.Users, user.Username)
+	}
+	render(w, "projectMembers", editor)
+}
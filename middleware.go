@@ -28,6 +28,25 @@ func auth(next http.Handler) http.Handler {
 	})
 }
 
+// davAuth authenticates CalDAV requests with HTTP basic authentication, as calendar
+// clients do not support the login form.
+func davAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var user models.User
+		var ok bool
+		user.Username, user.Password, ok = r.BasicAuth()
+		if !ok || !validateUser(&user) {
+			slog.Error("dav unauthorized", "user", user.Username)
+			w.Header().Set("WWW-Authenticate", `Basic realm="timetrace"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		user.Password = ""
+		ctx := context.WithValue(r.Context(), contextKey("user"), user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func getRequestUser(r *http.Request) models.User {
 	user, ok := r.Context().Value(contextKey("user")).(models.User)
 	if !ok {
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Absence kinds.
+const (
+	AbsenceVacation = "vacation"
+	AbsenceSick     = "sick"
+)
+
+// Absence represents a user's leave from Start to End, inclusive.
+type Absence struct {
+	ID      uuid.UUID
+	User    string
+	Kind    string
+	Start   time.Time
+	End     time.Time
+	Note    string
+	Updated time.Time
+}
+
+// AbsencePage represents the absences displayed to a user.
+type AbsencePage struct {
+	Absences    []Absence
+	Users       []string
+	AsAdmin     bool
+	DefaultDate string
+}
+
+// Holiday is a public holiday.
+type Holiday struct {
+	Date time.Time
+	Name string
+}
+
+// HolidayCalendar is a named set of public holidays, typically one per team or region.
+type HolidayCalendar struct {
+	Name     string
+	Holidays []Holiday
+	Updated  time.Time
+}
+
+// DaysOff maps the days, formatted as 2006-01-02, on which a user is not expected to
+// work to the reason for the absence.
+type DaysOff map[string]string
+
+// CalendarDay is a day in the calendar view.
+type CalendarDay struct {
+	Date    time.Time
+	InMonth bool
+	Worked  time.Duration
+	Off     string
+}
+
+// CalendarMonth is a month in the calendar view, in weeks starting on Monday.
+type CalendarMonth struct {
+	Month    time.Time
+	Previous string
+	Next     string
+	Weeks    [][]CalendarDay
+}
+
+// ValidAbsence reports whether kind is a known absence kind.
+func ValidAbsence(kind string) bool {
+	return kind == AbsenceVacation || kind == AbsenceSick
+}
+
+// Reason returns the reason the user is absent on the day of t, or blank if the user
+// is expected to work.
+func (d DaysOff) Reason(t time.Time) string {
+	return d[t.Format("2006-01-02")]
+}
+
+// FmtWorked returns the time worked in human readable form, or blank if none.
+func (c CalendarDay) FmtWorked() string {
+	if c.Worked == 0 {
+		return ""
+	}
+	return FmtDuration(c.Worked)
+}
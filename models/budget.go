@@ -0,0 +1,125 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Budget periods.
+const (
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// BudgetThresholds are the percentages of a budget at which alerts are raised.
+var BudgetThresholds = []int{80, 100}
+
+// BudgetAlert records that a budget threshold has been crossed.  Period is empty
+// for the total budget, otherwise the first day of the budget period.
+type BudgetAlert struct {
+	Period    string
+	Threshold int
+	Raised    time.Time
+}
+
+// BudgetStatus represents the consumption of a project budget.
+type BudgetStatus struct {
+	Project string
+	Color   string
+	Period  string
+	Budget  time.Duration
+	Used    time.Duration
+}
+
+// ValidPeriod reports whether period is a known budget period.
+func ValidPeriod(period string) bool {
+	return period == PeriodWeek || period == PeriodMonth
+}
+
+// PeriodStart returns the start of the budget period containing t.  Weeks start
+// on Monday.
+func PeriodStart(period string, t time.Time) time.Time {
+	year, month, day := t.Date()
+	if period == PeriodMonth {
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	}
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(year, month, day-offset, 0, 0, 0, 0, t.Location())
+}
+
+// PeriodEnd returns the start of the budget period following the one containing t.
+func PeriodEnd(period string, t time.Time) time.Time {
+	start := PeriodStart(period, t)
+	if period == PeriodMonth {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 7)
+}
+
+// FmtHours returns d as decimal hours, or blank if d is not positive.
+func FmtHours(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(d.Hours(), 'f', -1, 64)
+}
+
+// Label returns a description of the budget.
+func (b BudgetStatus) Label() string {
+	if b.Period == "" {
+		return "Total"
+	}
+	return "This " + b.Period
+}
+
+// Percent returns the percentage of the budget that has been used.
+func (b BudgetStatus) Percent() int {
+	if b.Budget <= 0 {
+		return 0
+	}
+	return int(b.Used * 100 / b.Budget)
+}
+
+// Bar returns the percentage used, capped at 100, for display in a progress bar.
+func (b BudgetStatus) Bar() int {
+	return min(b.Percent(), 100)
+}
+
+// Consumed returns the used time in human readable form.
+func (b BudgetStatus) Consumed() string {
+	return FmtDuration(b.Used)
+}
+
+// Remaining returns the unused time of the budget in human readable form.
+func (b BudgetStatus) Remaining() string {
+	return FmtDuration(max(b.Budget-b.Used, 0))
+}
+
+// Limit returns the budget in human readable form.
+func (b BudgetStatus) Limit() string {
+	return FmtDuration(b.Budget)
+}
+
+// Threshold returns the highest alert threshold that has been reached, or 0.
+func (b BudgetStatus) Threshold() int {
+	reached := 0
+	for _, threshold := range BudgetThresholds {
+		if b.Percent() >= threshold {
+			reached = threshold
+		}
+	}
+	return reached
+}
+
+// Warning returns a warning if an alert threshold has been reached.
+func (b BudgetStatus) Warning() string {
+	switch threshold := b.Threshold(); {
+	case threshold == 0:
+		return ""
+	case threshold >= 100:
+		return "budget exceeded"
+	default:
+		return fmt.Sprintf("%d%% of budget used", threshold)
+	}
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+)
+
+func TestPeriodStart(t *testing.T) {
+	// Wednesday
+	day := time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC)
+	should.BeEqual(t, PeriodStart(PeriodWeek, day), time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC))
+	should.BeEqual(t, PeriodEnd(PeriodWeek, day), time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC))
+	sunday := time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)
+	should.BeEqual(t, PeriodStart(PeriodWeek, sunday), time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC))
+	should.BeEqual(t, PeriodStart(PeriodMonth, day), time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+	should.BeEqual(t, PeriodEnd(PeriodMonth, day), time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func TestBudgetStatus(t *testing.T) {
+	status := BudgetStatus{Budget: 10 * time.Hour, Used: 7 * time.Hour}
+	should.BeEqual(t, status.Label(), "Total")
+	should.BeEqual(t, status.Percent(), 70)
+	should.BeEqual(t, status.Threshold(), 0)
+	should.BeEqual(t, status.Warning(), "")
+	should.BeEqual(t, status.Remaining(), FmtDuration(3*time.Hour))
+	status.Used = 8 * time.Hour
+	should.BeEqual(t, status.Threshold(), 80)
+	should.BeEqual(t, status.Warning(), "80% of budget used")
+	status.Used = 12 * time.Hour
+	status.Period = PeriodWeek
+	should.BeEqual(t, status.Label(), "This week")
+	should.BeEqual(t, status.Percent(), 120)
+	should.BeEqual(t, status.Bar(), 100)
+	should.BeEqual(t, status.Threshold(), 100)
+	should.BeEqual(t, status.Warning(), "budget exceeded")
+	should.BeEqual(t, status.Remaining(), FmtDuration(0))
+	should.BeEqual(t, FmtHours(90*time.Minute), "1.5")
+	should.BeEqual(t, FmtHours(0), "")
+}
@@ -0,0 +1,55 @@
+package models
+
+// Chart is an SVG chart drawn on the server.  Coordinates are in the units of a view
+// box of Width by Height, which the browser scales to fit.
+type Chart struct {
+	Title  string
+	Width  int
+	Height int
+	Rects  []ChartRect
+	Paths  []ChartPath
+	Labels []ChartLabel
+	Legend []ChartLegend
+}
+
+// ChartRect is a rectangle of a chart, such as a bar or a heatmap cell.  Title is
+// shown when hovering over it.
+type ChartRect struct {
+	X       float64
+	Y       float64
+	Width   float64
+	Height  float64
+	Color   string
+	Opacity float64
+	Title   string
+}
+
+// ChartPath is an SVG path of a chart, such as a slice of a donut.
+type ChartPath struct {
+	D     string
+	Color string
+	Title string
+}
+
+// ChartLabel is text on a chart.  Anchor is the SVG text-anchor: start, middle or end.
+type ChartLabel struct {
+	X      float64
+	Y      float64
+	Text   string
+	Anchor string
+}
+
+// ChartLegend is the color of a series of a chart.
+type ChartLegend struct {
+	Color string
+	Text  string
+}
+
+// Charts show the time of a report or of the current week: a stacked bar of each day
+// by project, the share of each project and a heatmap of hours by weekday and hour.
+// Charts are nil if there is no time to show.
+type Charts struct {
+	Days    *Chart
+	Share   *Chart
+	Heatmap *Chart
+}
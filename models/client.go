@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Client represents a customer for whom projects are undertaken.
+type Client struct {
+	ID       uuid.UUID
+	Name     string
+	Contact  string
+	Rate     int64
+	Currency string
+	Rounding Rounding
+	Updated  time.Time
+}
+
+// ProjectGroup represents the projects of a client for display.
+type ProjectGroup struct {
+	Client   string
+	Projects []Project
+}
+
+// Money returns the default rate of the client formatted for display.
+func (c Client) Money() string {
+	return FmtMoney(c.Rate)
+}
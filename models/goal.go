@@ -0,0 +1,114 @@
+package models
+
+import "time"
+
+// PeriodDay is the period of a daily goal.
+const PeriodDay = "day"
+
+// Goal is a user's target hours per day and per week.  An empty Project applies the
+// goal to time spent on all projects.  A zero target is not tracked.
+type Goal struct {
+	Project string
+	Daily   time.Duration
+	Weekly  time.Duration
+}
+
+// GoalProgress represents the progress toward a goal during a day or week.  Open
+// is set if the period has not ended.
+type GoalProgress struct {
+	Project string
+	Period  string
+	Target  time.Duration
+	Done    time.Duration
+	Open    bool
+}
+
+// GoalDay is the progress toward daily goals on a day.  Daily goals do not apply
+// on days off, for which Off holds the reason.
+type GoalDay struct {
+	Date  time.Time
+	Off   string
+	Goals []GoalProgress
+}
+
+// GoalSummary summarises the goals met and missed during a week.
+type GoalSummary struct {
+	Week     time.Time
+	Previous string
+	Next     string
+	Goals    []Goal
+	Projects []string
+	Days     []GoalDay
+	Weekly   []GoalProgress
+	Met      int
+	Missed   int
+}
+
+// Name returns the project of the goal, or "All projects".
+func (g Goal) Name() string {
+	if g.Project == "" {
+		return "All projects"
+	}
+	return g.Project
+}
+
+// DailyHours returns the daily target in hours, or blank if none.
+func (g Goal) DailyHours() string {
+	return FmtHours(g.Daily)
+}
+
+// WeeklyHours returns the weekly target in hours, or blank if none.
+func (g Goal) WeeklyHours() string {
+	return FmtHours(g.Weekly)
+}
+
+// Name returns the project of the goal, or "All projects".
+func (g GoalProgress) Name() string {
+	return Goal{Project: g.Project}.Name()
+}
+
+// Label returns a description of the goal.
+func (g GoalProgress) Label() string {
+	if g.Period == PeriodDay {
+		return g.Name() + " today"
+	}
+	return g.Name() + " this week"
+}
+
+// Percent returns the percentage of the target that has been worked.
+func (g GoalProgress) Percent() int {
+	if g.Target <= 0 {
+		return 0
+	}
+	return int(g.Done * 100 / g.Target)
+}
+
+// Bar returns the percentage worked, capped at 100, for display in a progress bar.
+func (g GoalProgress) Bar() int {
+	return min(g.Percent(), 100)
+}
+
+// Met reports whether the target has been reached.
+func (g GoalProgress) Met() bool {
+	return g.Done >= g.Target
+}
+
+// Missed reports whether the period has ended without the target being reached.
+func (g GoalProgress) Missed() bool {
+	return !g.Open && !g.Met()
+}
+
+// Worked returns the time worked in human readable form.
+func (g GoalProgress) Worked() string {
+	return FmtDuration(g.Done)
+}
+
+// Goal returns the target in human readable form.
+func (g GoalProgress) Goal() string {
+	return FmtDuration(g.Target)
+}
+
+// Remaining returns the time still to be worked to reach the target.
+func (g GoalProgress) Remaining() string {
+	return FmtDuration(max(g.Target-g.Done, 0))
+}
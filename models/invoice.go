@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invoice represents a bill to a client for the billable records in a period.
+type Invoice struct {
+	ID       uuid.UUID
+	Number   uint64
+	Client   string
+	Currency string
+	Start    time.Time
+	End      time.Time
+	Lines    []InvoiceLine
+	Records  []uuid.UUID
+	Total    int64
+	Voided   bool
+	Created  time.Time
+}
+
+// InvoiceLine is a line item of an invoice summarizing the records for a project/tag.
+type InvoiceLine struct {
+	Project  string
+	Tag      string
+	Duration time.Duration
+	Amount   int64
+}
+
+// InvoicePage represents the data for display of invoices.
+type InvoicePage struct {
+	Invoices    []Invoice
+	Projects    []string
+	Clients     []string
+	DefaultDate string
+}
+
+// Reference returns the invoice number formatted for display.
+func (i Invoice) Reference() string {
+	return fmt.Sprintf("INV-%05d", i.Number)
+}
+
+// Money returns the invoice total formatted for display.
+func (i Invoice) Money() string {
+	return FmtMoney(i.Total)
+}
+
+// Hours returns the line duration formatted for display.
+func (l InvoiceLine) Hours() string {
+	return FmtDuration(l.Duration)
+}
+
+// Money returns the line amount formatted for display.
+func (l InvoiceLine) Money() string {
+	return FmtMoney(l.Amount)
+}
@@ -0,0 +1,49 @@
+package models
+
+import "slices"
+
+// Project membership roles.
+const (
+	RoleOwner  = "owner"
+	RoleMember = "member"
+)
+
+// Member represents a user's membership of a project.
+type Member struct {
+	User string
+	Role string
+}
+
+// ValidRole reports whether role is a known membership role.
+func ValidRole(role string) bool {
+	return role == RoleOwner || role == RoleMember
+}
+
+// IsOpen reports whether the project has no members.  Projects without members
+// predate membership and remain available to all users.
+func (p *Project) IsOpen() bool {
+	return len(p.Members) == 0
+}
+
+// IsMember reports whether user may track time on the project.
+func (p *Project) IsMember(user string) bool {
+	return p.IsOpen() || slices.ContainsFunc(p.Members, func(m Member) bool { return m.User == user })
+}
+
+// IsOwner reports whether user is an owner of the project.
+func (p *Project) IsOwner(user string) bool {
+	return slices.ContainsFunc(p.Members, func(m Member) bool {
+		return m.User == user && m.Role == RoleOwner
+	})
+}
+
+// Owners returns the number of owners of the project.
+func (p *Project) Owners() int {
+	owners := 0
+	for _, member := range p.Members {
+		if member.Role == RoleOwner {
+			owners++
+		}
+	}
+	return owners
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/Kairum-Labs/should"
+)
+
+func TestMembership(t *testing.T) {
+	project := Project{}
+	should.BeTrue(t, project.IsOpen())
+	should.BeTrue(t, project.IsMember("anyone"))
+	should.BeFalse(t, project.IsOwner("anyone"))
+	project.Members = []Member{
+		{User: "boss", Role: RoleOwner},
+		{User: "worker", Role: RoleMember},
+	}
+	should.BeFalse(t, project.IsOpen())
+	should.BeTrue(t, project.IsMember("boss"))
+	should.BeTrue(t, project.IsMember("worker"))
+	should.BeFalse(t, project.IsMember("anyone"))
+	should.BeTrue(t, project.IsOwner("boss"))
+	should.BeFalse(t, project.IsOwner("worker"))
+	should.BeEqual(t, project.Owners(), 1)
+	should.BeTrue(t, ValidRole(RoleMember))
+	should.BeFalse(t, ValidRole("boss"))
+}
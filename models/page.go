@@ -12,8 +12,19 @@ type Page struct {
 	Version     string
 	Tracking    bool
 	Projects    []string
+	Favorites   []Project
+	Recent      []Project
+	Groups      []ProjectGroup
+	Archived    []string
+	Tags        []string
 	Status      StatusResponse
+	Budgets     []BudgetStatus
+	Goals       []GoalProgress
+	Flex        *FlexPeriod
+	FlexToday   *FlexPeriod
 	DefaultDate string
+	Users       []string
+	Charts      Charts
 }
 
 // Version reads version info from executable.
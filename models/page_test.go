@@ -31,7 +31,9 @@ func TestTracking(t *testing.T) {
 
 func TestRecords(t *testing.T) {
 	s := FmtDuration(time.Minute * 57)
-	should.BeEqual(t, s, "00:57 ( 1.0 Hours)")
+	should.BeEqual(t, s, "00:57 (0.95 Hours)")
+	should.BeEqual(t, FmtDuration(14*time.Minute+50*time.Second), "00:15 (0.25 Hours)")
+	should.BeEqual(t, FmtDuration(3*time.Hour+59*time.Minute), "03:59 (3.98 Hours)")
 	record := Record{
 		End:   time.Now(),
 		Start: time.Now().Add(time.Hour * -1),
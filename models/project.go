@@ -1,6 +1,7 @@
 package models
 
 import (
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,17 +12,48 @@ var (
 	trackedProject = map[string]string{}
 )
 
-// Project represents a project.
+// Project represents a project.  Budget is the total hours quoted for the project
+// and PeriodBudget the hours available in each BudgetPeriod; zero means no budget.
 type Project struct {
-	ID      uuid.UUID
-	Name    string
-	Active  bool
-	Updated time.Time
+	ID           uuid.UUID
+	Name         string
+	Description  string
+	Color        string
+	Order        int
+	Client       string
+	Active       bool
+	Billable     bool
+	Tasks        []string
+	Rates        []Rate
+	Members      []Member
+	Budget       time.Duration
+	PeriodBudget time.Duration
+	BudgetPeriod string
+	Alerts       []BudgetAlert
+	Rounding     Rounding
+	Updated      time.Time
+}
+
+// ProjectEditor represents a project being edited in the UI.
+type ProjectEditor struct {
+	Project
+
+	Users             []string
+	Clients           []string
+	Projects          []string
+	BudgetHours       string
+	PeriodBudgetHours string
 }
 
 // StartRequest is a request to start recording time for a given project.
 type StartRequest struct {
 	Project string
+	Task    string
+}
+
+// HasTask reports whether the project has the named task.
+func (p *Project) HasTask(task string) bool {
+	return slices.Contains(p.Tasks, task)
 }
 
 // IsTrackingActive checks if tracking has been activated for given user.
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate is an hourly rate, in cents, that applies from its effective date until
+// superseded by a later rate.  A rate with an empty User is the project default;
+// otherwise it overrides the default for that user.
+type Rate struct {
+	User      string
+	Amount    int64
+	Effective time.Time
+}
+
+// Money returns the rate formatted for display.
+func (r Rate) Money() string {
+	return FmtMoney(r.Amount)
+}
+
+// RateFor returns the hourly rate in cents for user at the given time.  User specific
+// rates take precedence over the project default.  Zero is returned if no rate applies.
+func (p *Project) RateFor(user string, at time.Time) int64 {
+	var rate, userRate *Rate
+	for i, r := range p.Rates {
+		if r.Effective.After(at) {
+			continue
+		}
+		switch r.User {
+		case "":
+			if rate == nil || r.Effective.After(rate.Effective) {
+				rate = &p.Rates[i]
+			}
+		case user:
+			if userRate == nil || r.Effective.After(userRate.Effective) {
+				userRate = &p.Rates[i]
+			}
+		}
+	}
+	if userRate != nil {
+		return userRate.Amount
+	}
+	if rate != nil {
+		return rate.Amount
+	}
+	return 0
+}
+
+// MaxRate is the highest hourly rate, in cents, that is accepted.
+const MaxRate = 100_000_00
+
+// Amount returns the value, in cents, of duration d at an hourly rate in cents.  It
+// is computed in whole seconds, which cannot overflow for rates up to MaxRate.
+func Amount(d time.Duration, rate int64) int64 {
+	return rate * int64(d/time.Second) / int64(time.Hour/time.Second)
+}
+
+// FmtMoney returns a human readable representation of an amount in cents.
+func FmtMoney(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// ParseMoney converts a decimal hourly rate such as "125.50" to cents.  Rates above
+// MaxRate are rejected.
+func ParseMoney(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(frac) > 2 {
+		return 0, errors.New("invalid amount " + s)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+	dollars, err := strconv.ParseUint(whole, 10, 32)
+	if err != nil {
+		return 0, errors.New("invalid amount " + s)
+	}
+	cents, err := strconv.ParseUint(frac, 10, 8)
+	if err != nil {
+		return 0, errors.New("invalid amount " + s)
+	}
+	if dollars*100+cents > MaxRate {
+		return 0, errors.New("rate cannot exceed " + FmtMoney(MaxRate))
+	}
+	return int64(dollars*100 + cents), nil
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+)
+
+func TestRateFor(t *testing.T) {
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	jun := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	project := Project{
+		Rates: []Rate{
+			{Amount: 10000, Effective: jan},
+			{Amount: 12000, Effective: jun},
+			{User: "senior", Amount: 15000, Effective: jun},
+		},
+	}
+	should.BeEqual(t, project.RateFor("junior", jan.Add(-time.Hour)), int64(0))
+	should.BeEqual(t, project.RateFor("junior", jan), int64(10000))
+	should.BeEqual(t, project.RateFor("junior", jun.Add(time.Hour)), int64(12000))
+	should.BeEqual(t, project.RateFor("senior", jan.Add(time.Hour)), int64(10000))
+	should.BeEqual(t, project.RateFor("senior", jun.Add(time.Hour)), int64(15000))
+}
+
+func TestMoney(t *testing.T) {
+	should.BeEqual(t, Amount(90*time.Minute, 10000), int64(15000))
+	should.BeEqual(t, FmtMoney(123456), "1234.56")
+	should.BeEqual(t, FmtMoney(-5), "-0.05")
+	cents, err := ParseMoney("125.5")
+	should.BeNil(t, err)
+	should.BeEqual(t, cents, int64(12550))
+	cents, err = ParseMoney("80")
+	should.BeNil(t, err)
+	should.BeEqual(t, cents, int64(8000))
+	_, err = ParseMoney("1.234")
+	should.NotBeNil(t, err)
+	_, err = ParseMoney("-3")
+	should.NotBeNil(t, err)
+	_, err = ParseMoney("100000.01")
+	should.NotBeNil(t, err)
+}
+
+func TestAmountOverflow(t *testing.T) {
+	// rate times nanoseconds would overflow after about 25 hours
+	should.BeEqual(t, Amount(1000*time.Hour, 100000), int64(100000000))
+	should.BeEqual(t, Amount(10*365*24*time.Hour, MaxRate), int64(876000000000))
+}
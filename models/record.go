@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,11 +10,16 @@ import (
 
 // Record represents a time record.
 type Record struct {
-	ID      uuid.UUID
-	Project string
-	User    string
-	Start   time.Time
-	End     time.Time
+	ID       uuid.UUID
+	Project  string
+	Task     string
+	User     string
+	Start    time.Time
+	End      time.Time
+	Note     string
+	Tags     []string
+	Billable bool
+	Invoice  uuid.UUID
 }
 
 // EditRecord represents a time record for editing in UI.
@@ -23,6 +29,47 @@ type EditRecord struct {
 	StartTime string
 	End       string
 	EndTime   string
+	Task      string
+	Tasks     []string
+	Note      string
+	Tags      []string
+	AllTags   []string
+	Billable  bool
+	Trim      bool
+	Errors    map[string]string
+}
+
+// NewEditRecord returns an EditRecord populated from a record.
+func NewEditRecord(r Record) EditRecord {
+	return EditRecord{
+		ID:        r.ID.String(),
+		Start:     r.Start.Format("2006-01-02"),
+		StartTime: r.Start.Format("15:04"),
+		End:       r.End.Format("2006-01-02"),
+		EndTime:   r.End.Format("15:04"),
+		Task:      r.Task,
+		Note:      r.Note,
+		Tags:      r.Tags,
+		Billable:  r.Billable,
+	}
+}
+
+// HasTag reports whether the record being edited has the given tag.
+func (e EditRecord) HasTag(tag string) bool {
+	return slices.Contains(e.Tags, tag)
+}
+
+// AddError records a validation error against the named form field.
+func (e *EditRecord) AddError(field, message string) {
+	if e.Errors == nil {
+		e.Errors = map[string]string{}
+	}
+	e.Errors[field] = message
+}
+
+// HasErrors reports whether any validation errors have been recorded.
+func (e *EditRecord) HasErrors() bool {
+	return len(e.Errors) > 0
 }
 
 // type Durations map[string]string
@@ -30,31 +77,50 @@ type EditRecord struct {
 // Duration reprents the time spend on a project.
 type Duration struct {
 	Project string
+	Color   string
 	Elapsed string
 }
 
+// Invoiced reports whether the record is included in an invoice.
+func (r *Record) Invoiced() bool {
+	return r.Invoice != uuid.Nil
+}
+
 // Duration returns the elapsed time from a time record.
 func (r *Record) Duration() time.Duration {
 	return r.End.Sub(r.Start)
 }
 
+// Clip trims the record to the part between start and end.  It reports whether any
+// of the record lies between them.  A record still being tracked must be given an end
+// before it is clipped.
+func (r *Record) Clip(start, end time.Time) bool {
+	if !r.End.After(start) || !r.Start.Before(end) {
+		return false
+	}
+	if r.Start.Before(start) {
+		r.Start = start
+	}
+	if r.End.After(end) {
+		r.End = end
+	}
+	return true
+}
+
+// FmtClock returns d as hours and minutes, such as 01:30.
+func FmtClock(d time.Duration) string {
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
 // FmtDuration returns a human readable representation of a duration
-// in hours:minute and decimal hours format.
+// in hours:minute and decimal hours format.  Both are of the duration rounded to
+// the minute, so that they agree with each other.
 func FmtDuration(d time.Duration) string {
 	d = d.Round(time.Minute)
 	h := d / time.Hour
-	d -= h * time.Hour
-	m := d / time.Minute
-	dm := int(m) / 6
-	if m%6 != 0 {
-		dm++
-	}
-	dh := h
-	if dm == 10 {
-		dh++
-		dm = 0
-	}
-	return fmt.Sprintf("%02d:%02d (%2d.%d Hours)", h, m, dh, dm)
+	m := (d - h*time.Hour) / time.Minute
+	return fmt.Sprintf("%02d:%02d (%.2f Hours)", h, m, d.Hours())
 }
 
 // StatusResponse provides a status for display.
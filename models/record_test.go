@@ -0,0 +1,27 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+)
+
+func TestClip(t *testing.T) {
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	next := day.AddDate(0, 0, 1)
+	record := Record{Start: day.Add(-time.Hour), End: day.Add(time.Hour)}
+	should.BeTrue(t, record.Clip(day, next))
+	should.BeEqual(t, record.Start, day)
+	should.BeEqual(t, record.Duration(), time.Hour)
+	record = Record{Start: next.Add(-time.Hour), End: next.Add(2 * time.Hour)}
+	should.BeTrue(t, record.Clip(day, next))
+	should.BeEqual(t, record.End, next)
+	record = Record{Start: day.Add(2 * time.Hour), End: day.Add(3 * time.Hour)}
+	should.BeTrue(t, record.Clip(day, next))
+	should.BeEqual(t, record.Duration(), time.Hour)
+	record = Record{Start: day.Add(-2 * time.Hour), End: day}
+	should.BeFalse(t, record.Clip(day, next))
+	record = Record{Start: next, End: next.Add(time.Hour)}
+	should.BeFalse(t, record.Clip(day, next))
+}
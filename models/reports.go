@@ -1,36 +1,191 @@
 package models
 
 import (
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// Report represents the time spent on a project.  It is a response to a ReportRequest.
+// Report represents the time spent on a project, or on a tag when grouped by tag.
+// It is a response to a ReportRequest.
 type Report struct {
+	Project     string
+	Client      string
+	Color       string
+	Tag         string
+	Total       string
+	Billable    string
+	NonBillable string
+	Amount      string
+	Items       []ReportRecord
+	Tasks       []TaskReport
+	Budgets     []BudgetStatus
+}
+
+// TaskReport represents the time spent on a task of a project.
+type TaskReport struct {
+	Task  string
+	Total string
+	Items []ReportRecord
+}
+
+// ReportGroup is the time of the records sharing a group, such as a day or a user,
+// with the subtotals of a nested grouping.
+type ReportGroup struct {
+	Name        string
+	Total       string
+	Billable    string
+	NonBillable string
+	Amount      string
+	Groups      []ReportGroup
+}
+
+// UserMatrix is the time of each user on each project of a report covering more
+// than one user.
+type UserMatrix struct {
+	Users  []string
+	Rows   []MatrixRow
+	Totals []string
+	Total  string
+}
+
+// MatrixRow is the time of each user on a project.
+type MatrixRow struct {
 	Project string
+	Cells   []string
 	Total   string
-	Items   []ReportRecord
 }
 
-// ReportRecord represents and individual report record.
+// ReportResponse is the complete response to a ReportRequest.  Reports covering
+// other users than the requester have a user by project matrix and the time of each
+// user by project.
+type ReportResponse struct {
+	Request     ReportRequest
+	Reports     []Report
+	Groups      []ReportGroup
+	Matrix      *UserMatrix
+	Users       []ReportGroup
+	Charts      Charts
+	Comparison  *Comparison
+	Clients     []Report
+	Flex        []FlexPeriod
+	Total       string
+	Billable    string
+	NonBillable string
+	Amount      string
+}
+
+// ReportRecord represents and individual report record.  Duration is the reported
+// duration of the record after rounding.
 type ReportRecord struct {
-	ID    uuid.UUID
-	Start time.Time
-	End   time.Time
+	ID       uuid.UUID
+	Task     string
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+	Note     string
+	Tags     []string
+	Billable bool
 }
 
-// ReportRequest contains data to initiate a report.
+// Report groupings.
+const (
+	GroupDay     = "day"
+	GroupWeek    = "week"
+	GroupMonth   = "month"
+	GroupProject = "project"
+	GroupUser    = "user"
+	GroupTag     = "tag"
+)
+
+// ReportGroupings are the groupings a report may use.
+var ReportGroupings = []string{GroupProject, GroupTag, GroupDay, GroupWeek, GroupMonth, GroupUser}
+
+// ReportRequest contains data to initiate a report.  Records are listed by project
+// or tag, or totalled by Group and then by SubGroup.  Reports cover the records of
+// the requester unless an admin selects Users or AllUsers.
 type ReportRequest struct {
-	Start   string `form:"start"   json:"start"`
-	End     string `form:"end"     json:"end"`
-	Project string `form:"project" json:"project"`
+	Start    string   `form:"start"    json:"start"`
+	End      string   `form:"end"      json:"end"`
+	Project  string   `form:"project"  json:"project"`
+	Client   string   `form:"client"   json:"client"`
+	Tag      string   `form:"tag"      json:"tag"`
+	Group    string   `form:"group"    json:"group"`
+	SubGroup string   `form:"subgroup" json:"subgroup"`
+	Users    []string `form:"users"    json:"users"`
+	AllUsers bool     `form:"allusers" json:"allusers"`
+	// Compare is CompareNone, ComparePrevious or CompareRange.
+	Compare      string `form:"compare"      json:"compare"`
+	CompareStart string `form:"compareStart" json:"compareStart"`
+	CompareEnd   string `form:"compareEnd"   json:"compareEnd"`
+}
+
+// Report comparisons.  A report may be compared with the period of the same length
+// before it or with another range of dates.
+const (
+	CompareNone     = ""
+	ComparePrevious = "previous"
+	CompareRange    = "range"
+)
+
+// Comparison compares the time of each project in a report with another period.
+type Comparison struct {
+	Start string
+	End   string
+	Rows  []ComparisonRow
+	Total ComparisonRow
+}
+
+// ComparisonRow is the time of a project in the period of a report and in the period
+// it is compared with.
+type ComparisonRow struct {
+	Project  string
+	Current  time.Duration
+	Previous time.Duration
+}
+
+// FmtCurrent returns the time in the period of the report as decimal hours.
+func (r ComparisonRow) FmtCurrent() string {
+	return fmt.Sprintf("%.2f", r.Current.Hours())
+}
+
+// FmtPrevious returns the time in the compared period as decimal hours.
+func (r ComparisonRow) FmtPrevious() string {
+	return fmt.Sprintf("%.2f", r.Previous.Hours())
+}
+
+// Delta returns the change in time as signed decimal hours, such as +1.50.
+func (r ComparisonRow) Delta() string {
+	return fmt.Sprintf("%+.2f", (r.Current - r.Previous).Hours())
+}
+
+// Percent returns the change in time as a signed percentage of the time in the
+// compared period, or "new" if there was none.
+func (r ComparisonRow) Percent() string {
+	if r.Previous == 0 {
+		if r.Current == 0 {
+			return "0%"
+		}
+		return "new"
+	}
+	return fmt.Sprintf("%+.0f%%", math.Round(100*float64(r.Current-r.Previous)/float64(r.Previous)))
+}
+
+// Aggregated reports whether the report totals records by group rather than
+// listing them.
+func (r ReportRequest) Aggregated() bool {
+	return r.SubGroup != "" || (r.Group != "" && r.Group != GroupProject && r.Group != GroupTag)
 }
 
-// DatabaseReportRequest represents a ReportRequest formatted for db queries.
+// DatabaseReportRequest represents a ReportRequest formatted for db queries.  Records
+// of any of Users match as well as those of User.
 type DatabaseReportRequest struct {
 	Start   time.Time
 	End     time.Time
 	Project string
 	User    string
+	Users   []string
+	Tag     string
 }
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+)
+
+func TestComparisonRow(t *testing.T) {
+	row := ComparisonRow{Current: 90 * time.Minute, Previous: time.Hour}
+	should.BeEqual(t, row.FmtCurrent(), "1.50")
+	should.BeEqual(t, row.FmtPrevious(), "1.00")
+	should.BeEqual(t, row.Delta(), "+0.50")
+	should.BeEqual(t, row.Percent(), "+50%")
+	row = ComparisonRow{Current: 20 * time.Minute, Previous: time.Hour}
+	should.BeEqual(t, row.Delta(), "-0.67")
+	should.BeEqual(t, row.Percent(), "-67%")
+	should.BeEqual(t, ComparisonRow{Current: time.Hour}.Percent(), "new")
+	should.BeEqual(t, ComparisonRow{}.Percent(), "0%")
+	should.BeEqual(t, ComparisonRow{}.Delta(), "+0.00")
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Rounding modes.
+const (
+	RoundNone    = ""
+	RoundNearest = "nearest"
+	RoundUp      = "up"
+	RoundDown    = "down"
+)
+
+// RoundingIncrements are the supported rounding increments, in minutes.
+var RoundingIncrements = []int{1, 5, 6, 15, 30}
+
+// Rounding is a policy for rounding the durations that are reported and billed.
+// Durations are rounded for each record, or once for each total if PerTotal is set.
+// Stored records are never rounded.
+type Rounding struct {
+	Mode     string
+	Minutes  int
+	PerTotal bool
+}
+
+// Valid reports whether the rounding has a known mode and increment.
+func (r Rounding) Valid() bool {
+	if r.Mode == RoundNone {
+		return true
+	}
+	return (r.Mode == RoundNearest || r.Mode == RoundUp || r.Mode == RoundDown) &&
+		slices.Contains(RoundingIncrements, r.Minutes)
+}
+
+// IsSet reports whether durations are rounded.
+func (r Rounding) IsSet() bool {
+	return r.Mode != RoundNone && r.Minutes > 0
+}
+
+// Round rounds d to the increment of the policy.
+func (r Rounding) Round(d time.Duration) time.Duration {
+	if !r.IsSet() {
+		return d
+	}
+	increment := time.Duration(r.Minutes) * time.Minute
+	switch r.Mode {
+	case RoundNearest:
+		return d.Round(increment)
+	case RoundUp:
+		if remainder := d % increment; remainder != 0 {
+			return d - remainder + increment
+		}
+		return d
+	default:
+		return d.Truncate(increment)
+	}
+}
+
+// Record returns the duration of a record rounded as required for per record rounding.
+func (r Rounding) Record(d time.Duration) time.Duration {
+	if r.PerTotal {
+		return d
+	}
+	return r.Round(d)
+}
+
+// Total returns a total duration rounded as required for per total rounding.
+func (r Rounding) Total(d time.Duration) time.Duration {
+	if !r.PerTotal {
+		return d
+	}
+	return r.Round(d)
+}
+
+// Increments returns the supported rounding increments for selection in the UI.
+func (r Rounding) Increments() []int {
+	return RoundingIncrements
+}
+
+// String returns a description of the policy.
+func (r Rounding) String() string {
+	if !r.IsSet() {
+		return "none"
+	}
+	scope := " per record"
+	if r.PerTotal {
+		scope = " per total"
+	}
+	return r.Mode + " " + strconv.Itoa(r.Minutes) + " minutes" + scope
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+)
+
+func TestRounding(t *testing.T) {
+	d := 22*time.Minute + 30*time.Second
+	should.BeEqual(t, Rounding{}.Round(d), d)
+	should.BeEqual(t, Rounding{Mode: RoundNearest, Minutes: 15}.Round(d), 30*time.Minute)
+	should.BeEqual(t, Rounding{Mode: RoundNearest, Minutes: 6}.Round(d), 24*time.Minute)
+	should.BeEqual(t, Rounding{Mode: RoundUp, Minutes: 5}.Round(d), 25*time.Minute)
+	should.BeEqual(t, Rounding{Mode: RoundUp, Minutes: 5}.Round(20*time.Minute), 20*time.Minute)
+	should.BeEqual(t, Rounding{Mode: RoundDown, Minutes: 30}.Round(d), time.Duration(0))
+	should.BeEqual(t, Rounding{Mode: RoundDown, Minutes: 1}.Round(d), 22*time.Minute)
+	perRecord := Rounding{Mode: RoundUp, Minutes: 15}
+	should.BeEqual(t, perRecord.Record(d), 30*time.Minute)
+	should.BeEqual(t, perRecord.Total(d), d)
+	perTotal := Rounding{Mode: RoundUp, Minutes: 15, PerTotal: true}
+	should.BeEqual(t, perTotal.Record(d), d)
+	should.BeEqual(t, perTotal.Total(d), 30*time.Minute)
+	should.BeTrue(t, Rounding{}.Valid())
+	should.BeTrue(t, perTotal.Valid())
+	should.BeFalse(t, Rounding{Mode: RoundUp, Minutes: 7}.Valid())
+	should.BeFalse(t, Rounding{Mode: "sideways", Minutes: 5}.Valid())
+	should.BeEqual(t, Rounding{}.String(), "none")
+	should.BeEqual(t, perTotal.String(), "up 15 minutes per total")
+}
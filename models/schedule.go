@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+)
+
+// Schedule is the number of hours a user is expected to work on each weekday,
+// indexed by time.Weekday.  Flextime is counted from Start.  No hours are expected
+// on a user's days off.
+type Schedule struct {
+	Hours [7]time.Duration
+	Start time.Time
+}
+
+// ScheduleDay represents the hours of a weekday in the schedule editor.  Field is
+// the name of the form field holding the hours.
+type ScheduleDay struct {
+	Day   string
+	Field string
+	Hours string
+}
+
+// ScheduleEditor represents a user's schedule being edited in the UI.
+type ScheduleEditor struct {
+	Username  string
+	Start     string
+	Days      []ScheduleDay
+	Holidays  string
+	Calendars []string
+	TimeZone  string
+}
+
+// FlexPeriod is the time worked during a period compared with the time expected by
+// a work schedule.
+type FlexPeriod struct {
+	Start    time.Time
+	End      time.Time
+	Expected time.Duration
+	Worked   time.Duration
+}
+
+// IsSet reports whether any working hours have been scheduled.
+func (s Schedule) IsSet() bool {
+	for _, hours := range s.Hours {
+		if hours > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Expected returns the hours expected to be worked on the day of t.  No hours are
+// expected before the schedule starts.
+func (s Schedule) Expected(t time.Time) time.Duration {
+	if t.Before(s.Start) {
+		return 0
+	}
+	return s.Hours[t.Weekday()]
+}
+
+// Weekdays returns the days of the week starting on Monday.
+func Weekdays() []time.Weekday {
+	return []time.Weekday{
+		time.Monday, time.Tuesday, time.Wednesday, time.Thursday,
+		time.Friday, time.Saturday, time.Sunday,
+	}
+}
+
+// Balance returns the overtime, or undertime if negative, of the period.
+func (f FlexPeriod) Balance() time.Duration {
+	return f.Worked - f.Expected
+}
+
+// FmtBalance returns the balance in human readable form with a leading sign.
+func (f FlexPeriod) FmtBalance() string {
+	return FmtBalance(f.Balance())
+}
+
+// FmtExpected returns the expected time in human readable form.
+func (f FlexPeriod) FmtExpected() string {
+	return FmtDuration(f.Expected)
+}
+
+// FmtWorked returns the worked time in human readable form.
+func (f FlexPeriod) FmtWorked() string {
+	return FmtDuration(f.Worked)
+}
+
+// LastDay returns the last day of the period.
+func (f FlexPeriod) LastDay() time.Time {
+	return f.End.AddDate(0, 0, -1)
+}
+
+// FmtBalance returns a human readable representation of a positive or negative
+// duration in hours:minute and decimal hours format.
+func FmtBalance(d time.Duration) string {
+	if d < 0 {
+		return "-" + FmtDuration(-d)
+	}
+	return "+" + FmtDuration(d)
+}
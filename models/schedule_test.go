@@ -0,0 +1,22 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+)
+
+func TestSchedule(t *testing.T) {
+	schedule := Schedule{Start: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)}
+	should.BeFalse(t, schedule.IsSet())
+	schedule.Hours[time.Monday] = 8 * time.Hour
+	should.BeTrue(t, schedule.IsSet())
+	should.BeEqual(t, schedule.Expected(time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)), 8*time.Hour)
+	should.BeEqual(t, schedule.Expected(time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC)), time.Duration(0))
+	should.BeEqual(t, schedule.Expected(time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)), time.Duration(0))
+	should.BeEqual(t, FmtBalance(90*time.Minute), "+"+FmtDuration(90*time.Minute))
+	should.BeEqual(t, FmtBalance(-90*time.Minute), "-"+FmtDuration(90*time.Minute))
+	period := FlexPeriod{Expected: 8 * time.Hour, Worked: 6 * time.Hour}
+	should.BeEqual(t, period.Balance(), -2*time.Hour)
+}
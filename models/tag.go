@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+// Tag represents a label describing the type of activity of a time record.
+type Tag struct {
+	Name    string
+	Updated time.Time
+}
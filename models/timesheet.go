@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Timesheet is a grid of the time worked on each project on each day of a week or
+// month.
+type Timesheet struct {
+	Period   string
+	Start    time.Time
+	Previous string
+	Next     string
+	Days     []time.Time
+	Rows     []TimesheetRow
+	Totals   []TimesheetCell
+	Total    time.Duration
+}
+
+// TimesheetRow is the time worked on a project on each day of a timesheet.  Hours may
+// be entered in the empty cells of editable rows.
+type TimesheetRow struct {
+	Project  string
+	Color    string
+	Editable bool
+	Cells    []TimesheetCell
+	Total    time.Duration
+}
+
+// TimesheetCell is the time worked on a project, or on all projects for a daily
+// total, on a day.
+type TimesheetCell struct {
+	Project string
+	Date    time.Time
+	Worked  time.Duration
+}
+
+// TimesheetRecords are the records of a timesheet cell.
+type TimesheetRecords struct {
+	Project string
+	Date    time.Time
+	Period  string
+	Items   []ReportRecord
+}
+
+// FmtTotal returns the total time of the timesheet.
+func (t Timesheet) FmtTotal() string {
+	return FmtClock(t.Total)
+}
+
+// FmtTotal returns the total time of the row.
+func (r TimesheetRow) FmtTotal() string {
+	return FmtClock(r.Total)
+}
+
+// Day returns the date of the cell as used in requests.
+func (c TimesheetCell) Day() string {
+	return c.Date.Format("2006-01-02")
+}
+
+// FmtWorked returns the time worked, or blank if there is none.
+func (c TimesheetCell) FmtWorked() string {
+	if c.Worked == 0 {
+		return ""
+	}
+	return FmtClock(c.Worked)
+}
@@ -6,10 +6,35 @@ import (
 
 // User represents a user.
 type User struct {
-	Username string `form:"username" json:"username"`
-	Password string `form:"password" json:"password"`
-	IsAdmin  bool
-	Updated  time.Time
+	Username  string `form:"username" json:"username"`
+	Password  string `form:"password" json:"password"`
+	IsAdmin   bool
+	Favorites []string
+	Goals     []Goal
+	Schedule  Schedule
+	Holidays  string
+	TimeZone  string
+	FeedToken string
+	Updated   time.Time
+}
+
+// Location returns the user's time zone, or the local time zone if none is set.
+func (u User) Location() *time.Location {
+	if u.TimeZone == "" {
+		return time.Local
+	}
+	location, err := time.LoadLocation(u.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return location
+}
+
+// Feed represents the calendar subscription of a user.  URL is blank if the user has
+// no subscription token.
+type Feed struct {
+	URL  string
+	Days int
 }
 
 // Editor represents the an editor of a user.
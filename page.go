@@ -1,9 +1,13 @@
 package main
 
 import (
+	"cmp"
 	"log"
 	"log/slog"
+	"maps"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/devilcove/timetraced/database"
@@ -27,17 +31,116 @@ func displayStatus(w http.ResponseWriter, r *http.Request) {
 	render(w, "content", page)
 }
 
+// recentProjects is the number of recently used projects shown in the project list.
+const recentProjects = 3
+
+// sortProjects orders projects by sort order and then name.
+func sortProjects(projects []models.Project) {
+	slices.SortStableFunc(projects, func(a, b models.Project) int {
+		if c := cmp.Compare(a.Order, b.Order); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+}
+
+// groupProjects groups projects by client.  Projects without a client are last.
+func groupProjects(projects []models.Project) []models.ProjectGroup {
+	groups := []models.ProjectGroup{}
+	byClient := map[string][]models.Project{}
+	for _, project := range projects {
+		byClient[project.Client] = append(byClient[project.Client], project)
+	}
+	for _, client := range slices.Sorted(maps.Keys(byClient)) {
+		if client == "" {
+			continue
+		}
+		groups = append(groups, models.ProjectGroup{Client: client, Projects: byClient[client]})
+	}
+	if unassigned, ok := byClient[""]; ok {
+		groups = append(groups, models.ProjectGroup{Projects: unassigned})
+	}
+	return groups
+}
+
+// pinnedProjects returns the user's favourite projects and the most recently used
+// projects that are not favourites.
+func pinnedProjects(user string, projects []models.Project) ([]models.Project, []models.Project) {
+	favorites := []models.Project{}
+	recent := []models.Project{}
+	if user == "" {
+		return favorites, recent
+	}
+	u, err := database.GetUser(user)
+	if err != nil {
+		slog.Error("get user", "user", user, "error", err)
+		return favorites, recent
+	}
+	for _, project := range projects {
+		if slices.Contains(u.Favorites, project.Name) {
+			favorites = append(favorites, project)
+		}
+	}
+	records, err := database.GetAllRecordsForUser(user)
+	if err != nil {
+		slog.Error("get records", "user", user, "error", err)
+		return favorites, recent
+	}
+	slices.SortFunc(records, func(a, b models.Record) int {
+		return b.Start.Compare(a.Start)
+	})
+	for _, record := range records {
+		if len(recent) == recentProjects {
+			break
+		}
+		if slices.Contains(u.Favorites, record.Project) ||
+			slices.ContainsFunc(recent, func(p models.Project) bool { return p.Name == record.Project }) {
+			continue
+		}
+		index := slices.IndexFunc(projects, func(p models.Project) bool { return p.Name == record.Project })
+		if index >= 0 {
+			recent = append(recent, projects[index])
+		}
+	}
+	return favorites, recent
+}
+
 func populatePage(user string) models.Page {
 	page := models.GetPage()
 	page.Tracking = models.IsTrackingActive(user)
+	// an unknown user is treated as a non-admin and sees only open projects
+	u, _ := database.GetUser(user)
 	projects, err := database.GetAllProjects()
 	if err != nil {
 		slog.Error("get projects", "error", err)
 	} else {
+		active := []models.Project{}
+		records := budgetRecords(projects)
+		billing, err := loadBilling()
+		if err != nil {
+			slog.Error("load billing", "error", err)
+		}
 		for _, project := range projects {
+			if !canAccessProject(u, project) {
+				continue
+			}
 			page.Projects = append(page.Projects, project.Name)
+			if project.Active {
+				active = append(active, project)
+				budgets := projectBudgets(project, records[project.Name], billing.rounding(project.Name))
+				page.Budgets = append(page.Budgets, budgets...)
+			} else {
+				page.Archived = append(page.Archived, project.Name)
+			}
 		}
+		sortProjects(active)
+		page.Groups = groupProjects(active)
+		page.Favorites, page.Recent = pinnedProjects(user, active)
 	}
+	page.Tags = tagNames()
+	page.Goals = currentGoals(u)
+	page.Flex, page.FlexToday = flexBalance(u)
+	page.Charts = weekCharts(u)
 	status, err := getStatus(user)
 	if err != nil {
 		log.Println("getStatus", err)
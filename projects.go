@@ -1,10 +1,14 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/devilcove/timetraced/database"
@@ -22,8 +26,10 @@ func showProjects(w http.ResponseWriter, r *http.Request) {
 }
 
 func addProject(w http.ResponseWriter, r *http.Request) {
+	user := getRequestUser(r)
 	project := models.Project{
-		Name: r.FormValue("name"),
+		Name:    r.FormValue("name"),
+		Members: []models.Member{{User: user.Username, Role: models.RoleOwner}},
 	}
 	if regexp.MustCompile(`\s+`).MatchString(project.Name) || project.Name == "" {
 		processError(w, http.StatusBadRequest, "invalid project name")
@@ -48,18 +54,354 @@ func addProject(w http.ResponseWriter, r *http.Request) {
 	displayMain(w, r)
 }
 
+func displayEditProject(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to edit projects")
+		return
+	}
+	project, err := database.GetProject(r.PathValue("name"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, "error reading project "+err.Error())
+		return
+	}
+	renderProjectEditor(w, project)
+}
+
+func editProject(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to edit projects")
+		return
+	}
+	project, err := database.GetProject(r.PathValue("name"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, "error reading project "+err.Error())
+		return
+	}
+	project.Client = r.FormValue("client")
+	if project.Client != "" {
+		if _, err := database.GetClient(project.Client); err != nil {
+			processError(w, http.StatusBadRequest, "client does not exist")
+			return
+		}
+	}
+	project.Billable = r.FormValue("billable") != ""
+	project.Description = strings.TrimSpace(r.FormValue("description"))
+	// a color input always has a value, so no color is chosen with a checkbox
+	project.Color = r.FormValue("color")
+	if r.FormValue("nocolor") != "" {
+		project.Color = ""
+	}
+	if project.Color != "" && !regexp.MustCompile(`^#[0-9a-fA-F]{6}$`).MatchString(project.Color) {
+		processError(w, http.StatusBadRequest, "invalid color")
+		return
+	}
+	project.Order = 0
+	if order := r.FormValue("order"); order != "" {
+		project.Order, err = strconv.Atoi(order)
+		if err != nil {
+			processError(w, http.StatusBadRequest, "invalid sort order")
+			return
+		}
+	}
+	if err := setBudget(r, &project); err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	project.Rounding, err = parseRounding(r)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	project.Updated = time.Now()
+	if err := database.SaveProject(&project); err != nil {
+		processError(w, http.StatusInternalServerError, "error saving project "+err.Error())
+		return
+	}
+	slog.Info("project updated", "project", project.Name)
+	renderProjectEditor(w, project)
+}
+
+func addRate(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to edit projects")
+		return
+	}
+	project, err := database.GetProject(r.PathValue("name"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, "error reading project "+err.Error())
+		return
+	}
+	amount, err := models.ParseMoney(r.FormValue("amount"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	effective, err := time.ParseInLocation("2006-01-02", r.FormValue("effective"), time.Local)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rate := models.Rate{
+		User:      r.FormValue("user"),
+		Amount:    amount,
+		Effective: effective,
+	}
+	if rate.User != "" {
+		if _, err := database.GetUser(rate.User); err != nil {
+			processError(w, http.StatusBadRequest, "user does not exist")
+			return
+		}
+	}
+	project.Rates = slices.DeleteFunc(project.Rates, func(existing models.Rate) bool {
+		return existing.User == rate.User && existing.Effective.Equal(rate.Effective)
+	})
+	project.Rates = append(project.Rates, rate)
+	slices.SortFunc(project.Rates, func(a, b models.Rate) int {
+		return a.Effective.Compare(b.Effective)
+	})
+	project.Updated = time.Now()
+	if err := database.SaveProject(&project); err != nil {
+		processError(w, http.StatusInternalServerError, "error saving project "+err.Error())
+		return
+	}
+	slog.Info("rate added", "project", project.Name, "user", rate.User, "rate", amount)
+	renderProjectEditor(w, project)
+}
+
+func renderProjectEditor(w http.ResponseWriter, project models.Project) {
+	editor := models.ProjectEditor{
+		Project:           project,
+		BudgetHours:       models.FmtHours(project.Budget),
+		PeriodBudgetHours: models.FmtHours(project.PeriodBudget),
+	}
+	projects, err := database.GetAllProjects()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, p := range projects {
+		if p.Name != project.Name {
+			editor.Projects = append(editor.Projects, p.Name)
+		}
+	}
+	users, err := database.GetAllUsers()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, user := range users {
+		editor.Users = append(editor.Users, user.Username)
+	}
+	clients, err := database.GetAllClients()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, client := range clients {
+		editor.Clients = append(editor.Clients, client.Name)
+	}
+	render(w, "editProject", editor)
+}
+
+func toggleFavorite(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	project, err := database.GetProject(name)
+	if err != nil {
+		processError(w, http.StatusBadRequest, "error reading project "+err.Error())
+		return
+	}
+	if !canAccessProject(getRequestUser(r), project) {
+		processError(w, http.StatusUnauthorized, "you are not a member of this project")
+		return
+	}
+	user, err := database.GetUser(getRequestUser(r).Username)
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if slices.Contains(user.Favorites, name) {
+		user.Favorites = slices.DeleteFunc(user.Favorites, func(f string) bool { return f == name })
+	} else {
+		user.Favorites = append(user.Favorites, name)
+	}
+	if err := database.SaveUser(&user); err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	showProjects(w, r)
+}
+
+func archiveProject(w http.ResponseWriter, r *http.Request) {
+	setProjectActive(w, r, false)
+}
+
+func reactivateProject(w http.ResponseWriter, r *http.Request) {
+	setProjectActive(w, r, true)
+}
+
+func setProjectActive(w http.ResponseWriter, r *http.Request, active bool) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to edit projects")
+		return
+	}
+	project, err := database.GetProject(r.PathValue("name"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, "error reading project "+err.Error())
+		return
+	}
+	project.Active = active
+	project.Updated = time.Now()
+	if err := database.SaveProject(&project); err != nil {
+		processError(w, http.StatusInternalServerError, "error saving project "+err.Error())
+		return
+	}
+	slog.Info("project updated", "project", project.Name, "active", active)
+	renderProjectEditor(w, project)
+}
+
+func deleteProject(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to delete projects")
+		return
+	}
+	name := r.PathValue("name")
+	if _, err := database.GetProject(name); err != nil {
+		processError(w, http.StatusBadRequest, "error reading project "+err.Error())
+		return
+	}
+	records, err := database.GetAllRecords()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, record := range records {
+		if record.Project == name && record.End.IsZero() {
+			processError(w, http.StatusBadRequest, "project is being tracked by "+record.User)
+			return
+		}
+	}
+	switch r.FormValue("records") {
+	case "reassign":
+		target := r.FormValue("target")
+		if target == name {
+			processError(w, http.StatusBadRequest, "cannot reassign records to the deleted project")
+			return
+		}
+		if _, err := database.GetProject(target); err != nil {
+			processError(w, http.StatusBadRequest, "error reading project "+err.Error())
+			return
+		}
+		err = database.ReassignRecords(name, target)
+	case "purge":
+		err = database.DeleteProjectRecords(name)
+	default:
+		processError(w, http.StatusBadRequest, "choose to reassign or purge records")
+		return
+	}
+	if errors.Is(err, database.ErrRecordInvoiced) {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := database.DeleteProject(name); err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	slog.Info("deleted", "project", name, "records", r.FormValue("records"))
+	showProjects(w, r)
+}
+
+func addTask(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to edit projects")
+		return
+	}
+	project, err := database.GetProject(r.PathValue("name"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, "error reading project "+err.Error())
+		return
+	}
+	task := r.FormValue("task")
+	if regexp.MustCompile(`\s+`).MatchString(task) || task == "" {
+		processError(w, http.StatusBadRequest, "invalid task name")
+		return
+	}
+	if project.HasTask(task) {
+		processError(w, http.StatusBadRequest, "task exists")
+		return
+	}
+	project.Tasks = append(project.Tasks, task)
+	project.Updated = time.Now()
+	if err := database.SaveProject(&project); err != nil {
+		processError(w, http.StatusInternalServerError, "error saving project "+err.Error())
+		return
+	}
+	slog.Info("task added", "project", project.Name, "task", task)
+	renderProjectEditor(w, project)
+}
+
+func deleteTask(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	if !editor.IsAdmin {
+		processError(w, http.StatusUnauthorized, "you are not authorized to edit projects")
+		return
+	}
+	project, err := database.GetProject(r.PathValue("name"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, "error reading project "+err.Error())
+		return
+	}
+	task := r.PathValue("task")
+	if !project.HasTask(task) {
+		processError(w, http.StatusBadRequest, "no such task")
+		return
+	}
+	project.Tasks = slices.DeleteFunc(project.Tasks, func(t string) bool { return t == task })
+	project.Updated = time.Now()
+	if err := database.SaveProject(&project); err != nil {
+		processError(w, http.StatusInternalServerError, "error saving project "+err.Error())
+		return
+	}
+	slog.Info("task deleted", "project", project.Name, "task", task)
+	renderProjectEditor(w, project)
+}
+
 func start(w http.ResponseWriter, r *http.Request) {
 	proj := r.PathValue("name")
+	task := r.PathValue("task")
 	user := getRequestUser(r)
 	project, err := database.GetProject(proj)
 	if err != nil {
 		processError(w, http.StatusBadRequest, "error reading project "+err.Error())
 		return
 	}
+	if !canAccessProject(user, project) {
+		processError(w, http.StatusUnauthorized, "you are not a member of this project")
+		return
+	}
 	if !project.Active {
 		processError(w, http.StatusBadRequest, "project is not active")
 		return
 	}
+	if task != "" && !project.HasTask(task) {
+		processError(w, http.StatusBadRequest, "no such task")
+		return
+	}
+	_ = r.ParseForm()
+	tags := parseTags(r.Form["tags"])
+	if err := registerTags(tags); err != nil {
+		processError(w, http.StatusInternalServerError, "failed to save tags "+err.Error())
+		return
+	}
 	if models.IsTrackingActive(user.Username) {
 		if err := stopE(user.Username); err != nil {
 			processError(w, http.StatusInternalServerError, err.Error())
@@ -67,17 +409,21 @@ func start(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	record := models.Record{
-		ID:      uuid.New(),
-		Project: proj,
-		User:    user.Username,
-		Start:   time.Now(),
+		ID:       uuid.New(),
+		Project:  proj,
+		Task:     task,
+		User:     user.Username,
+		Start:    time.Now(),
+		Note:     strings.TrimSpace(r.FormValue("note")),
+		Tags:     tags,
+		Billable: project.Billable,
 	}
 	if err := database.SaveRecord(&record); err != nil {
 		processError(w, http.StatusInternalServerError, "failed to save record "+err.Error())
 		return
 	}
 	models.TrackingActive(user.Username, project)
-	slog.Info("tracking started", "project", project.Name)
+	slog.Info("tracking started", "project", project.Name, "task", task)
 	render(w, "content", populatePage(user.Username))
 }
 
@@ -91,7 +437,9 @@ func stopE(user string) error {
 			record.End = time.Now()
 			if err := database.SaveRecord(&record); err != nil {
 				slog.Error("failed to save updated record", "error", err)
+				continue
 			}
+			checkBudget(record.Project, alertBudget)
 		}
 	}
 	slog.Info("tracking stopped", "project", models.Tracked(user))
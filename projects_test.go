@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -124,7 +125,9 @@ func TestStartStopProject(t *testing.T) {
 	})
 	t.Run("active", func(t *testing.T) {
 		w := httptest.NewRecorder()
-		req := httptest.NewRequest(http.MethodPost, "/projects/start/test", nil)
+		payload := bodyParams("note", " fixing bugs ", "tags", "Dev, review")
+		req := httptest.NewRequest(http.MethodPost, "/projects/start/test", payload)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 		req.AddCookie(adminLogin())
 		router.ServeHTTP(w, req)
 		should.BeEqual(t, w.Code, http.StatusOK)
@@ -132,6 +135,10 @@ func TestStartStopProject(t *testing.T) {
 		should.BeNil(t, err)
 		should.BeEqual(t, len(records), 1)
 		should.BeEqual(t, records[0].End.IsZero(), true)
+		should.BeEqual(t, records[0].Note, "fixing bugs")
+		should.BeEqual(t, records[0].Tags, []string{"dev", "review"})
+		_, err = database.GetTag("review")
+		should.BeNil(t, err)
 	})
 	t.Run("startdifferent", func(t *testing.T) {
 		w := httptest.NewRecorder()
@@ -165,6 +172,337 @@ func TestStartStopProject(t *testing.T) {
 	})
 }
 
+func TestEditProject(t *testing.T) {
+	deleteAllProjects()
+	createTestProjects()
+	deleteAllUsers()
+	createAdmin()
+	should.BeNil(t, createTestUser(models.User{Username: "test", Password: "testing"}))
+	post := func(cookie *http.Cookie, url string, params ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, url, bodyParams(params...))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	t.Run("display", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/projects/edit/test", nil)
+		req.AddCookie(adminLogin())
+		router.ServeHTTP(w, req)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "Edit Project test")
+	})
+	t.Run("notAdmin", func(t *testing.T) {
+		cookie := testLogin(models.User{Username: "test", Password: "testing"})
+		w := post(cookie, "/projects/edit/test", "billable", "on")
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+	})
+	t.Run("missing", func(t *testing.T) {
+		w := post(adminLogin(), "/projects/edit/junk", "billable", "on")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+	t.Run("billable", func(t *testing.T) {
+		w := post(adminLogin(), "/projects/edit/test", "billable", "on")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		project, err := database.GetProject("test")
+		should.BeNil(t, err)
+		should.BeTrue(t, project.Billable)
+	})
+	t.Run("rate", func(t *testing.T) {
+		w := post(adminLogin(), "/projects/rates/test", "effective", "2026-01-01", "amount", "100")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		w = post(adminLogin(), "/projects/rates/test",
+			"effective", "2026-01-01", "amount", "150.50", "user", "test")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "150.50")
+		w = post(adminLogin(), "/projects/rates/test", "effective", "2026-01-01", "amount", "110")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		project, err := database.GetProject("test")
+		should.BeNil(t, err)
+		should.BeEqual(t, len(project.Rates), 2)
+		should.BeEqual(t, project.RateFor("admin", time.Now()), int64(11000))
+		should.BeEqual(t, project.RateFor("test", time.Now()), int64(15050))
+	})
+	t.Run("badRate", func(t *testing.T) {
+		w := post(adminLogin(), "/projects/rates/test", "effective", "2026-01-01", "amount", "ten")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		w = post(adminLogin(), "/projects/rates/test", "effective", "junk", "amount", "10")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		w = post(adminLogin(), "/projects/rates/test",
+			"effective", "2026-01-01", "amount", "10", "user", "nobody")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+	t.Run("metadata", func(t *testing.T) {
+		w := post(adminLogin(), "/projects/edit/test",
+			"description", " client work ", "color", "#1a2b3c", "order", "2")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		project, err := database.GetProject("test")
+		should.BeNil(t, err)
+		should.BeEqual(t, project.Description, "client work")
+		should.BeEqual(t, project.Color, "#1a2b3c")
+		should.BeEqual(t, project.Order, 2)
+	})
+	t.Run("noColor", func(t *testing.T) {
+		w := post(adminLogin(), "/projects/edit/test", "color", "#808080", "nocolor", "on")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		project, err := database.GetProject("test")
+		should.BeNil(t, err)
+		should.BeEqual(t, project.Color, "")
+	})
+	t.Run("badColor", func(t *testing.T) {
+		w := post(adminLogin(), "/projects/edit/test", "color", "red")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "invalid color")
+	})
+	t.Run("rounding", func(t *testing.T) {
+		w := post(adminLogin(), "/projects/edit/test",
+			"roundingMode", "up", "roundingMinutes", "6", "roundingScope", "total")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		project, err := database.GetProject("test")
+		should.BeNil(t, err)
+		should.BeEqual(t, project.Rounding,
+			models.Rounding{Mode: models.RoundUp, Minutes: 6, PerTotal: true})
+		w = post(adminLogin(), "/projects/edit/test", "roundingMode", "up", "roundingMinutes", "7")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "invalid rounding")
+	})
+	t.Run("badOrder", func(t *testing.T) {
+		w := post(adminLogin(), "/projects/edit/test", "order", "first")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "invalid sort order")
+	})
+}
+
+func TestFavoriteProjects(t *testing.T) {
+	deleteAllRecords()
+	deleteAllProjects()
+	createTestProjects()
+	deleteAllUsers()
+	createAdmin()
+	favorite := func(name string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/projects/favorite/"+name, nil)
+		req.AddCookie(adminLogin())
+		router.ServeHTTP(w, req)
+		return w
+	}
+	t.Run("missing", func(t *testing.T) {
+		w := favorite("junk")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+	t.Run("pin", func(t *testing.T) {
+		w := favorite("test2")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "Pinned")
+		user, err := database.GetUser("admin")
+		should.BeNil(t, err)
+		should.BeEqual(t, user.Favorites, []string{"test2"})
+	})
+	t.Run("unpin", func(t *testing.T) {
+		w := favorite("test2")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.BeFalse(t, strings.Contains(w.Body.String(), "Pinned"))
+		user, err := database.GetUser("admin")
+		should.BeNil(t, err)
+		should.BeEmpty(t, user.Favorites)
+	})
+}
+
+func TestPinnedProjects(t *testing.T) {
+	deleteAllRecords()
+	deleteAllUsers()
+	createAdmin()
+	user, err := database.GetUser("admin")
+	should.BeNil(t, err)
+	user.Favorites = []string{"b"}
+	should.BeNil(t, database.SaveUser(&user))
+	projects := []models.Project{
+		{Name: "d", Order: 1},
+		{Name: "c"},
+		{Name: "b"},
+		{Name: "a", Order: 1},
+	}
+	sortProjects(projects)
+	names := []string{}
+	for _, project := range projects {
+		names = append(names, project.Name)
+	}
+	should.BeEqual(t, names, []string{"b", "c", "a", "d"})
+	now := time.Now()
+	for i, name := range []string{"a", "b", "c", "gone"} {
+		should.BeNil(t, database.SaveRecord(&models.Record{
+			ID:      uuid.New(),
+			Project: name,
+			User:    "admin",
+			Start:   now.Add(time.Duration(i-10) * time.Hour),
+			End:     now.Add(time.Duration(i-10)*time.Hour + time.Minute),
+		}))
+	}
+	favorites, recent := pinnedProjects("admin", projects)
+	should.BeEqual(t, len(favorites), 1)
+	should.BeEqual(t, favorites[0].Name, "b")
+	should.BeEqual(t, len(recent), 2)
+	should.BeEqual(t, recent[0].Name, "c")
+	should.BeEqual(t, recent[1].Name, "a")
+}
+
+func TestTasks(t *testing.T) {
+	deleteAllRecords()
+	deleteAllProjects()
+	createTestProjects()
+	deleteAllUsers()
+	createAdmin()
+	request := func(method, url string, params ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, bodyParams(params...))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(adminLogin())
+		router.ServeHTTP(w, req)
+		return w
+	}
+	t.Run("add", func(t *testing.T) {
+		w := request(http.MethodPost, "/projects/tasks/test", "task", "design")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "/projects/tasks/test/design")
+		project, err := database.GetProject("test")
+		should.BeNil(t, err)
+		should.BeEqual(t, project.Tasks, []string{"design"})
+	})
+	t.Run("invalid", func(t *testing.T) {
+		w := request(http.MethodPost, "/projects/tasks/test", "task", "bad task")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		w = request(http.MethodPost, "/projects/tasks/test", "task", "design")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "task exists")
+	})
+	t.Run("list", func(t *testing.T) {
+		w := request(http.MethodGet, "/projects/list/")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "/projects/start/test/design")
+	})
+	t.Run("startMissing", func(t *testing.T) {
+		w := request(http.MethodPost, "/projects/start/test/build")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "no such task")
+	})
+	t.Run("start", func(t *testing.T) {
+		w := request(http.MethodPost, "/projects/start/test/design")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		w = request(http.MethodPost, "/projects/stop/")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		records, err := database.GetAllRecords()
+		should.BeNil(t, err)
+		should.BeEqual(t, len(records), 1)
+		should.BeEqual(t, records[0].Task, "design")
+	})
+	t.Run("report", func(t *testing.T) {
+		today := time.Now().Format(time.DateOnly)
+		w := request(http.MethodPost, "/reports/", "start", today, "end", today)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "Task design")
+	})
+	t.Run("delete", func(t *testing.T) {
+		w := request(http.MethodDelete, "/projects/tasks/test/design")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		w = request(http.MethodDelete, "/projects/tasks/test/design")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+}
+
+func TestProjectLifecycle(t *testing.T) {
+	deleteAllRecords()
+	deleteAllProjects()
+	createTestProjects()
+	deleteAllUsers()
+	createAdmin()
+	should.BeNil(t, createTestUser(models.User{Username: "test", Password: "testing"}))
+	request := func(cookie *http.Cookie, method, url string, params ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, bodyParams(params...))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	saveRecord := func(project string, end time.Time) models.Record {
+		record := models.Record{
+			ID:      uuid.New(),
+			Project: project,
+			User:    "test",
+			Start:   time.Now().Add(-time.Hour),
+			End:     end,
+		}
+		should.BeNil(t, database.SaveRecord(&record))
+		return record
+	}
+	t.Run("notAdmin", func(t *testing.T) {
+		cookie := testLogin(models.User{Username: "test", Password: "testing"})
+		w := request(cookie, http.MethodPost, "/projects/archive/test")
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+		w = request(cookie, http.MethodDelete, "/projects/test?records=purge")
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+	})
+	t.Run("archive", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodPost, "/projects/archive/test")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "Reactivate")
+		w = request(adminLogin(), http.MethodGet, "/projects/list/")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.BeFalse(t, strings.Contains(w.Body.String(), "/projects/start/test\""))
+		should.ContainSubstring(t, w.Body.String(), "<h2>Archived</h2>")
+		w = request(adminLogin(), http.MethodPost, "/projects/start/test")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+	t.Run("reactivate", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodPost, "/projects/reactivate/test")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		project, err := database.GetProject("test")
+		should.BeNil(t, err)
+		should.BeTrue(t, project.Active)
+	})
+	t.Run("missing", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodPost, "/projects/archive/junk")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		w = request(adminLogin(), http.MethodDelete, "/projects/junk?records=purge")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+	t.Run("noChoice", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodDelete, "/projects/test")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "choose to reassign or purge")
+	})
+	t.Run("tracked", func(t *testing.T) {
+		open := saveRecord("golf", time.Time{})
+		w := request(adminLogin(), http.MethodDelete, "/projects/golf?records=purge")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "project is being tracked")
+		should.BeNil(t, database.DeleteRecord(open.ID))
+	})
+	t.Run("reassign", func(t *testing.T) {
+		record := saveRecord("test", time.Now())
+		w := request(adminLogin(), http.MethodDelete, "/projects/test?records=reassign&target=test")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		w = request(adminLogin(), http.MethodDelete, "/projects/test?records=reassign&target=junk")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		w = request(adminLogin(), http.MethodDelete, "/projects/test?records=reassign&target=test2")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		_, err := database.GetProject("test")
+		should.NotBeNil(t, err)
+		moved, err := database.GetRecord(record.ID)
+		should.BeNil(t, err)
+		should.BeEqual(t, moved.Project, "test2")
+	})
+	t.Run("purge", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodDelete, "/projects/test2?records=purge")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		records, err := database.GetAllRecords()
+		should.BeNil(t, err)
+		should.BeEmpty(t, records)
+	})
+}
+
 func deleteAllProjects() {
 	projects, _ := database.GetAllProjects()
 	for _, p := range projects {
@@ -204,3 +542,81 @@ func createTestProjects() {
 		Updated: time.Now(),
 	})
 }
+
+func TestProjectMembers(t *testing.T) {
+	deleteAllRecords()
+	deleteAllProjects()
+	deleteAllUsers()
+	createAdmin()
+	owner := models.User{Username: "owner", Password: "testing"}
+	other := models.User{Username: "other", Password: "testing"}
+	should.BeNil(t, createTestUser(owner))
+	should.BeNil(t, createTestUser(other))
+	request := func(cookie *http.Cookie, method, url string, params ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, bodyParams(params...))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	w := request(testLogin(owner), http.MethodPost, "/projects/", "name", "private")
+	should.BeEqual(t, w.Code, http.StatusOK)
+	t.Run("creatorIsOwner", func(t *testing.T) {
+		project, err := database.GetProject("private")
+		should.BeNil(t, err)
+		should.BeEqual(t, project.Members, []models.Member{{User: "owner", Role: models.RoleOwner}})
+	})
+	t.Run("nonMember", func(t *testing.T) {
+		w := request(testLogin(other), http.MethodGet, "/projects/list/")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.BeFalse(t, strings.Contains(w.Body.String(), "/projects/start/private"))
+		w = request(testLogin(other), http.MethodPost, "/projects/start/private")
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+		w = request(testLogin(other), http.MethodPost, "/projects/members/private",
+			"user", "other", "role", "member")
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		w := request(testLogin(owner), http.MethodPost, "/projects/members/private",
+			"user", "other", "role", "boss")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		w = request(testLogin(owner), http.MethodPost, "/projects/members/private",
+			"user", "nobody", "role", "member")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+	t.Run("addMember", func(t *testing.T) {
+		w := request(testLogin(owner), http.MethodPost, "/projects/members/private",
+			"user", "other", "role", "member")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "/projects/members/private/other")
+		w = request(testLogin(other), http.MethodGet, "/projects/list/")
+		should.ContainSubstring(t, w.Body.String(), "/projects/start/private")
+		w = request(testLogin(other), http.MethodPost, "/projects/start/private")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.BeNil(t, stopE("other"))
+	})
+	t.Run("memberCannotManage", func(t *testing.T) {
+		w := request(testLogin(other), http.MethodGet, "/projects/members/private")
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+		w = request(testLogin(other), http.MethodDelete, "/projects/members/private/owner")
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+	})
+	t.Run("lastOwner", func(t *testing.T) {
+		w := request(testLogin(owner), http.MethodDelete, "/projects/members/private/owner")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "project must have an owner")
+		w = request(testLogin(owner), http.MethodPost, "/projects/members/private",
+			"user", "owner", "role", "member")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+	t.Run("adminOverride", func(t *testing.T) {
+		w := request(adminLogin(), http.MethodPost, "/projects/start/private")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.BeNil(t, stopE("admin"))
+		w = request(adminLogin(), http.MethodDelete, "/projects/members/private/other")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		w = request(testLogin(other), http.MethodPost, "/projects/start/private")
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+	})
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"maps"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/devilcove/timetraced/database"
@@ -9,42 +12,54 @@ import (
 	"github.com/google/uuid"
 )
 
+// getStatus returns the time recorded today by user.  Project durations are rounded
+// according to each project's rounding policy; the current session is not rounded.
 func getStatus(user string) (models.StatusResponse, error) {
 	durations := make(map[string]time.Duration)
 	status := models.Status{}
 	response := models.StatusResponse{}
-	records, err := database.GetTodaysRecordsForUser(user)
+	records, err := database.GetAllRecordsForUser(user)
+	if err != nil {
+		return response, err
+	}
+	billing, err := loadBilling()
 	if err != nil {
 		return response, err
 	}
 	status.Current = models.Tracked(user)
+	today := startOfDay(time.Now().In(userLocation(user)))
 	for _, record := range records {
 		if record.End.IsZero() {
 			record.End = time.Now()
 			status.Elapsed = record.Duration()
 		}
-		durations[record.Project] += record.End.Sub(record.Start)
-		status.DailyTotal += record.Duration()
-		if record.Project == status.Current {
-			status.Total += record.Duration()
+		// only the part of a record started yesterday which falls on today counts
+		if !record.Clip(today, today.AddDate(0, 0, 1)) {
+			continue
 		}
+		durations[record.Project] += billing.rounding(record.Project).Record(record.Duration())
+	}
+	for project, d := range durations {
+		durations[project] = billing.rounding(project).Total(d)
+		status.DailyTotal += durations[project]
 	}
+	status.Total = durations[status.Current]
 	response.Current = status.Current
 	response.Elapsed = models.FmtDuration(status.Elapsed)
 	response.CurrentTotal = models.FmtDuration(status.Total)
 	response.DailyTotal = models.FmtDuration(status.DailyTotal)
-	for k := range durations {
-		value := models.FmtDuration(durations[k])
-		duration := models.Duration{
+	for _, k := range slices.Sorted(maps.Keys(durations)) {
+		response.Durations = append(response.Durations, models.Duration{
 			Project: k,
-			Elapsed: value,
-		}
-		response.Durations = append(response.Durations, duration)
+			Color:   billing.projects[k].Color,
+			Elapsed: models.FmtDuration(durations[k]),
+		})
 	}
 	return response, nil
 }
 
 func getRecord(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
 	id, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
 		processError(w, http.StatusBadRequest, err.Error())
@@ -55,35 +70,104 @@ func getRecord(w http.ResponseWriter, r *http.Request) {
 		processError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	render(w, "editRecord", record)
+	if !canEditRecord(editor, record) {
+		processError(w, http.StatusUnauthorized, "you are not authorized to edit this record")
+		return
+	}
+	edit := models.NewEditRecord(record)
+	renderEditRecord(w, record.Project, edit)
 }
 
 func editRecord(w http.ResponseWriter, r *http.Request) {
+	editor := getRequestUser(r)
+	_ = r.ParseForm()
 	edit := models.EditRecord{
 		ID:        r.PathValue("id"),
 		Start:     r.FormValue("Start"),
 		StartTime: r.FormValue("StartTime"),
 		End:       r.FormValue("End"),
 		EndTime:   r.FormValue("EndTime"),
+		Task:      r.FormValue("Task"),
+		Note:      strings.TrimSpace(r.FormValue("Note")),
+		Tags:      parseTags(r.Form["tags"]),
+		Billable:  r.FormValue("Billable") != "",
+		Trim:      r.FormValue("Trim") != "",
 	}
 	record, err := database.GetRecord(uuid.MustParse(edit.ID))
 	if err != nil {
 		processError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	record.End, err = time.Parse("2006-01-0215:04", edit.End+edit.EndTime)
+	if !canEditRecord(editor, record) {
+		processError(w, http.StatusUnauthorized, "you are not authorized to edit this record")
+		return
+	}
+	if record.Invoiced() {
+		processError(w, http.StatusBadRequest, database.ErrRecordInvoiced.Error())
+		return
+	}
+	record.End, err = time.ParseInLocation("2006-01-0215:04", edit.End+edit.EndTime, time.Local)
 	if err != nil {
 		processError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	record.Start, err = time.Parse("2006-01-0215:04", edit.Start+edit.StartTime)
+	record.Start, err = time.ParseInLocation(
+		"2006-01-0215:04",
+		edit.Start+edit.StartTime,
+		time.Local,
+	)
 	if err != nil {
 		processError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if edit.Task != "" {
+		project, err := database.GetProject(record.Project)
+		if err != nil {
+			processError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !project.HasTask(edit.Task) {
+			edit.AddError("Task", "no such task")
+		}
+	}
+	record.Task = edit.Task
+	record.Note = edit.Note
+	record.Tags = edit.Tags
+	record.Billable = edit.Billable
+	trimmed, err := validateRecord(record, &edit)
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if edit.HasErrors() {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		renderEditRecord(w, record.Project, edit)
+		return
+	}
+	if err := registerTags(record.Tags); err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, neighbour := range trimmed {
+		if err := database.SaveRecord(&neighbour); err != nil {
+			processError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
 	if err := database.SaveRecord(&record); err != nil {
 		processError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	checkBudget(record.Project, alertBudget)
 	displayStatus(w, r)
 }
+
+// renderEditRecord renders the record edit form with the tags and the tasks of project
+// available for selection.
+func renderEditRecord(w http.ResponseWriter, project string, edit models.EditRecord) {
+	edit.AllTags = tagNames()
+	if p, err := database.GetProject(project); err == nil {
+		edit.Tasks = p.Tasks
+	}
+	render(w, "editRecord", edit)
+}
@@ -10,6 +10,8 @@ import (
 
 	"github.com/Kairum-Labs/should"
 	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
 )
 
 func TestRecords(t *testing.T) {
@@ -42,8 +44,8 @@ func TestRecords(t *testing.T) {
 		should.ContainSubstring(t, string(body), "invalid UUID")
 	})
 	t.Run("edit", func(t *testing.T) {
-		start := time.Now().Add(time.Hour - 1)
-		end := time.Now()
+		start := time.Now().Add(time.Hour * -72)
+		end := time.Now().Add(time.Hour * -71)
 		w := httptest.NewRecorder()
 		payload := bodyParams(
 			"ID", ID,
@@ -51,6 +53,7 @@ func TestRecords(t *testing.T) {
 			"StartTime", formatTimeOnly(start),
 			"End", end.Format(time.DateOnly),
 			"EndTime", formatTimeOnly(end),
+			"Note", "code review",
 		)
 		r := httptest.NewRequest(http.MethodPost, url, payload)
 		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -58,11 +61,36 @@ func TestRecords(t *testing.T) {
 		router.ServeHTTP(w, r)
 		should.BeEqual(t, w.Result().StatusCode, http.StatusOK)
 		record, err := database.GetRecord(records[0].ID)
+		should.BeEqual(t, record.Note, "code review")
 		t.Log("record", record.Start, "start", start)
 		should.BeNil(t, err)
 		should.BeEqual(t, record.Start.Format(time.DateOnly), start.Format(time.DateOnly))
 		should.BeEqual(t, record.End.Format(time.DateOnly), end.Format(time.DateOnly))
 	})
+	t.Run("billable", func(t *testing.T) {
+		start := time.Now().Add(time.Hour * -72)
+		end := time.Now().Add(time.Hour * -71)
+		edit := func(params ...string) models.Record {
+			w := httptest.NewRecorder()
+			params = append(params,
+				"ID", ID,
+				"Start", start.Format(time.DateOnly),
+				"StartTime", formatTimeOnly(start),
+				"End", end.Format(time.DateOnly),
+				"EndTime", formatTimeOnly(end),
+			)
+			r := httptest.NewRequest(http.MethodPost, url, bodyParams(params...))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.AddCookie(adminLogin())
+			router.ServeHTTP(w, r)
+			should.BeEqual(t, w.Result().StatusCode, http.StatusOK)
+			record, err := database.GetRecord(records[0].ID)
+			should.BeNil(t, err)
+			return record
+		}
+		should.BeTrue(t, edit("Billable", "on").Billable)
+		should.BeFalse(t, edit().Billable)
+	})
 	t.Run("editBadID", func(t *testing.T) {
 		start := time.Now().Add(time.Hour - 1)
 		end := time.Now()
@@ -126,6 +154,93 @@ func TestRecords(t *testing.T) {
 	})
 }
 
+func TestEditRecordValidation(t *testing.T) {
+	deleteAllRecords()
+	deleteAllUsers()
+	createAdmin()
+	should.BeNil(t, createTestUser(models.User{Username: "test", Password: "testing"}))
+	should.BeNil(t, createTestUser(models.User{Username: "other", Password: "testing"}))
+	now := time.Now().Truncate(time.Minute)
+	first := models.Record{
+		ID:      uuid.New(),
+		Project: "timetrace",
+		User:    "test",
+		Start:   now.Add(time.Hour * -5),
+		End:     now.Add(time.Hour * -4),
+	}
+	second := models.Record{
+		ID:      uuid.New(),
+		Project: "golf",
+		User:    "test",
+		Start:   now.Add(time.Hour * -3),
+		End:     now.Add(time.Hour * -2),
+	}
+	should.BeNil(t, database.SaveRecord(&first))
+	should.BeNil(t, database.SaveRecord(&second))
+	edit := func(cookie *http.Cookie, start, end time.Time, extra ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		params := []string{
+			"Start", start.Format(time.DateOnly),
+			"StartTime", formatTimeOnly(start),
+			"End", end.Format(time.DateOnly),
+			"EndTime", formatTimeOnly(end),
+		}
+		payload := bodyParams(append(params, extra...)...)
+		r := httptest.NewRequest(http.MethodPost, "/records/"+second.ID.String(), payload)
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.AddCookie(cookie)
+		router.ServeHTTP(w, r)
+		return w
+	}
+	owner := testLogin(models.User{Username: "test", Password: "testing"})
+	t.Run("notOwner", func(t *testing.T) {
+		cookie := testLogin(models.User{Username: "other", Password: "testing"})
+		w := edit(cookie, now.Add(time.Hour*-3), now.Add(time.Hour*-2))
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+		r := httptest.NewRequest(http.MethodGet, "/records/"+second.ID.String(), nil)
+		r.AddCookie(cookie)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+	})
+	t.Run("endBeforeStart", func(t *testing.T) {
+		w := edit(owner, now.Add(time.Hour*-2), now.Add(time.Hour*-3))
+		should.BeEqual(t, w.Code, http.StatusUnprocessableEntity)
+		should.ContainSubstring(t, w.Body.String(), "end must be after start")
+		should.ContainSubstring(t, w.Body.String(), "Edit Record")
+	})
+	t.Run("future", func(t *testing.T) {
+		w := edit(owner, now.Add(time.Hour*-1), now.Add(time.Hour*2))
+		should.BeEqual(t, w.Code, http.StatusUnprocessableEntity)
+		should.ContainSubstring(t, w.Body.String(), "end cannot be in the future")
+	})
+	t.Run("span", func(t *testing.T) {
+		w := edit(owner, now.Add(time.Hour*-24*14), now.Add(time.Hour*-1))
+		should.BeEqual(t, w.Code, http.StatusUnprocessableEntity)
+		should.ContainSubstring(t, w.Body.String(), "record cannot span more than")
+	})
+	t.Run("overlap", func(t *testing.T) {
+		w := edit(owner, now.Add(time.Minute*-270), now.Add(time.Hour*-2))
+		should.BeEqual(t, w.Code, http.StatusUnprocessableEntity)
+		should.ContainSubstring(t, w.Body.String(), "overlaps timetrace record")
+	})
+	t.Run("trim", func(t *testing.T) {
+		w := edit(owner, now.Add(time.Minute*-270), now.Add(time.Hour*-2), "Trim", "on")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		trimmed, err := database.GetRecord(first.ID)
+		should.BeNil(t, err)
+		should.BeTrue(t, trimmed.End.Equal(now.Add(time.Minute*-270)))
+		edited, err := database.GetRecord(second.ID)
+		should.BeNil(t, err)
+		should.BeTrue(t, edited.Start.Equal(now.Add(time.Minute*-270)))
+	})
+	t.Run("trimEnclosed", func(t *testing.T) {
+		w := edit(owner, now.Add(time.Hour*-6), now.Add(time.Hour*-2), "Trim", "on")
+		should.BeEqual(t, w.Code, http.StatusUnprocessableEntity)
+		should.ContainSubstring(t, w.Body.String(), "cannot trim a record enclosed by this one")
+	})
+}
+
 func formatTimeOnly(t time.Time) string {
 	s := t.Format(time.TimeOnly)
 	index := strings.LastIndex(s, ":")
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
+	"maps"
+	"math"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/devilcove/timetraced/database"
@@ -10,72 +16,612 @@ import (
 )
 
 func getReport(w http.ResponseWriter, r *http.Request) {
-	var err error
-	projectsToQuery := []string{}
 	user := getRequestUser(r)
-	dbRequest := models.DatabaseReportRequest{
-		User: user.Username,
-	}
-	reportRequest := models.ReportRequest{
-		Start:   r.FormValue("start"),
-		End:     r.FormValue("end"),
-		Project: r.FormValue("project"),
-	}
-	slog.Info("getReport", "request", reportRequest)
-	dbRequest.Start, err = time.Parse("2006-01-02", reportRequest.Start)
+	reportRequest, dbRequest, err := parseReportRequest(r, user)
 	if err != nil {
-		processError(w, http.StatusBadRequest, err.Error())
+		processError(w, reportErrorStatus(err), err.Error())
 		return
 	}
-	dbRequest.End, err = time.Parse("2006-01-02", reportRequest.End)
+	slog.Info("getReport", "request", reportRequest)
+	billing, err := loadBilling()
 	if err != nil {
 		processError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	if reportRequest.Project == "" {
-		allProjects, err := database.GetAllProjects()
-		if err != nil {
-			processError(w, http.StatusInternalServerError, err.Error())
-			return
-		}
-		for _, project := range allProjects {
-			projectsToQuery = append(projectsToQuery, project.Name)
-		}
-	} else {
-		projectsToQuery = append(projectsToQuery, reportRequest.Project)
-	}
-	displayRecords := []models.Report{}
+	projectsToQuery := reportProjects(reportRequest, billing)
+	// the request days are parsed in the time zone of the requester
+	location := dbRequest.Start.Location()
+	response := models.ReportResponse{Request: reportRequest}
+	totals := reportTotals{}
+	clientTotals := map[string]*reportTotals{}
+	tagged := map[string][]models.Record{}
+	all := []models.Record{}
+	budgeted := budgetRecords(slices.Collect(maps.Values(billing.projects)))
 	for _, project := range projectsToQuery {
-		displayRecord := models.Report{}
-		reportRecord := models.ReportRecord{}
-		reportRecords := []models.ReportRecord{}
 		dbRequest.Project = project
 		data, err := database.GetReportRecords(dbRequest)
 		if err != nil {
 			processError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
-		var total time.Duration
+		client := billing.client(project)
+		projectTotals := reportTotals{}
 		for _, d := range data {
-			recordTotal := d.End.Sub(d.Start)
-			total += recordTotal
-			reportRecord.End = d.End
-			reportRecord.Start = d.Start
-			reportRecord.ID = d.ID
-			reportRecords = append(reportRecords, reportRecord)
-		}
-		if total != 0 {
+			projectTotals.add(d, billing)
+		}
+		projectTotals.round(billing.rounding(project))
+		totals.merge(projectTotals)
+		// clients only have a subtotal if they have records in the report
+		if client.Name != "" && len(data) > 0 {
+			if clientTotals[client.Name] == nil {
+				clientTotals[client.Name] = &reportTotals{}
+			}
+			clientTotals[client.Name].merge(projectTotals)
+		}
+		all = append(all, data...)
+		if reportRequest.Aggregated() {
+			continue
+		}
+		if reportRequest.Group == models.GroupTag {
+			for _, d := range data {
+				for _, tag := range groupKeys(models.GroupTag, d, location) {
+					tagged[tag] = append(tagged[tag], d)
+				}
+			}
+			continue
+		}
+		if displayRecord, ok := buildReport(data, billing, billing.rounding(project), location); ok {
 			displayRecord.Project = project
-			displayRecord.Total = models.FmtDuration(total)
-			displayRecord.Items = reportRecords
-			displayRecords = append(displayRecords, displayRecord)
+			displayRecord.Client = client.Name
+			displayRecord.Color = billing.projects[project].Color
+			displayRecord.Budgets = projectBudgets(billing.projects[project], budgeted[project], billing.rounding(project))
+			response.Reports = append(response.Reports, displayRecord)
 		}
 	}
-	render(w, "results", displayRecords)
+	response.Reports = append(response.Reports, tagReports(tagged, billing, location)...)
+	response.Clients = clientReports(clientTotals, billing)
+	if err := reportDetails(&response, dbRequest, user.Username, all, projectsToQuery, billing); err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response.Total = models.FmtDuration(totals.total)
+	response.Billable = models.FmtDuration(totals.billable)
+	response.NonBillable = models.FmtDuration(totals.nonBillable)
+	response.Amount = totals.money()
+	render(w, "results", response)
+}
+
+// reportDetails adds the groups, comparison and charts of the records of a report to
+// the response, with flextime for a report on the requester or user totals for a
+// report on other users.
+func reportDetails(
+	response *models.ReportResponse,
+	dbRequest models.DatabaseReportRequest,
+	username string,
+	records []models.Record,
+	projects []string,
+	billing billing,
+) error {
+	request := response.Request
+	location := userLocation(username)
+	if request.Aggregated() {
+		response.Groups = groupRecords(records, request.Group, request.SubGroup, billing, location)
+	}
+	if request.Compare != models.CompareNone {
+		comparison, err := reportComparison(request, dbRequest, projects, billing, location)
+		if err != nil {
+			return err
+		}
+		response.Comparison = comparison
+	}
+	colors := map[string]string{}
+	for name, project := range billing.projects {
+		colors[name] = project.Color
+	}
+	response.Charts = buildCharts(records, colors, dbRequest.Start, dbRequest.End.AddDate(0, 0, 1), location)
+	if slices.Equal(dbRequest.Users, []string{username}) {
+		response.Flex = reportFlex(username, dbRequest.Start, dbRequest.End)
+	} else {
+		response.Matrix = userMatrix(records, billing)
+		response.Users = groupRecords(records, models.GroupUser, models.GroupProject, billing, location)
+	}
+	return nil
+}
+
+// errReportUsers is returned when a user who is not an admin requests a report on
+// other users.
+var errReportUsers = errors.New("you are not authorized to report on other users")
+
+// parseReportRequest reads a report request from the form.  The dates are in the
+// time zone of the user.
+func parseReportRequest(
+	r *http.Request,
+	user models.User,
+) (models.ReportRequest, models.DatabaseReportRequest, error) {
+	var err error
+	reportRequest := models.ReportRequest{
+		Start:        r.FormValue("start"),
+		End:          r.FormValue("end"),
+		Project:      r.FormValue("project"),
+		Client:       r.FormValue("client"),
+		Tag:          r.FormValue("tag"),
+		Group:        r.FormValue("group"),
+		SubGroup:     r.FormValue("subgroup"),
+		AllUsers:     r.FormValue("allusers") != "",
+		Compare:      r.FormValue("compare"),
+		CompareStart: r.FormValue("compareStart"),
+		CompareEnd:   r.FormValue("compareEnd"),
+	}
+	// FormValue has parsed the form
+	reportRequest.Users = r.Form["users"]
+	dbRequest := models.DatabaseReportRequest{
+		Tag: reportRequest.Tag,
+	}
+	dbRequest.Users, err = reportUsers(reportRequest, user)
+	if err != nil {
+		return reportRequest, dbRequest, err
+	}
+	if reportRequest.Group != "" && !slices.Contains(models.ReportGroupings, reportRequest.Group) {
+		return reportRequest, dbRequest, errors.New("invalid group")
+	}
+	if reportRequest.SubGroup != "" && !slices.Contains(models.ReportGroupings, reportRequest.SubGroup) {
+		return reportRequest, dbRequest, errors.New("invalid subgroup")
+	}
+	location := userLocation(user.Username)
+	dbRequest.Start, err = time.ParseInLocation("2006-01-02", reportRequest.Start, location)
+	if err != nil {
+		return reportRequest, dbRequest, err
+	}
+	dbRequest.End, err = time.ParseInLocation("2006-01-02", reportRequest.End, location)
+	if err != nil {
+		return reportRequest, dbRequest, err
+	}
+	_, _, err = comparePeriod(reportRequest, dbRequest, location)
+	return reportRequest, dbRequest, err
+}
+
+// comparePeriod returns the first and last day of the period a report is compared
+// with.  The previous period has as many days as the report and ends the day before
+// it starts.
+func comparePeriod(
+	request models.ReportRequest,
+	dbRequest models.DatabaseReportRequest,
+	location *time.Location,
+) (time.Time, time.Time, error) {
+	switch request.Compare {
+	case models.CompareNone:
+		return time.Time{}, time.Time{}, nil
+	case models.ComparePrevious:
+		days := int(math.Round(dbRequest.End.Sub(dbRequest.Start).Hours()/24)) + 1
+		return dbRequest.Start.AddDate(0, 0, -days), dbRequest.Start.AddDate(0, 0, -1), nil
+	case models.CompareRange:
+		start, err := time.ParseInLocation("2006-01-02", request.CompareStart, location)
+		if err != nil {
+			return start, start, errors.New("invalid comparison start")
+		}
+		end, err := time.ParseInLocation("2006-01-02", request.CompareEnd, location)
+		if err != nil || end.Before(start) {
+			return start, end, errors.New("invalid comparison end")
+		}
+		return start, end, nil
+	default:
+		return time.Time{}, time.Time{}, errors.New("invalid comparison")
+	}
+}
+
+// reportComparison compares the time of each project of a report with the time in
+// the period it is compared with.  Project totals are rounded as in the report.
+func reportComparison(
+	request models.ReportRequest,
+	dbRequest models.DatabaseReportRequest,
+	projects []string,
+	billing billing,
+	location *time.Location,
+) (*models.Comparison, error) {
+	start, end, err := comparePeriod(request, dbRequest, location)
+	if err != nil {
+		return nil, err
+	}
+	comparison := &models.Comparison{
+		Start: start.Format("2006-01-02"),
+		End:   end.Format("2006-01-02"),
+		Total: models.ComparisonRow{Project: "Total"},
+	}
+	other := dbRequest
+	other.Start, other.End = start, end
+	for _, project := range projects {
+		row := models.ComparisonRow{Project: project}
+		for period, query := range []models.DatabaseReportRequest{dbRequest, other} {
+			query.Project = project
+			data, err := database.GetReportRecords(query)
+			if err != nil {
+				return nil, err
+			}
+			totals := reportTotals{}
+			for _, d := range data {
+				totals.add(d, billing)
+			}
+			totals.round(billing.rounding(project))
+			if period == 0 {
+				row.Current = totals.total
+			} else {
+				row.Previous = totals.total
+			}
+		}
+		if row.Current == 0 && row.Previous == 0 {
+			continue
+		}
+		comparison.Rows = append(comparison.Rows, row)
+		comparison.Total.Current += row.Current
+		comparison.Total.Previous += row.Previous
+	}
+	return comparison, nil
+}
+
+// reportUsers returns the sorted names of the users covered by a report request,
+// which defaults to the requester.  Only admins may report on other users.
+func reportUsers(request models.ReportRequest, user models.User) ([]string, error) {
+	if !request.AllUsers && len(request.Users) == 0 {
+		return []string{user.Username}, nil
+	}
+	if !user.IsAdmin && (request.AllUsers || slices.ContainsFunc(request.Users, func(name string) bool {
+		return name != user.Username
+	})) {
+		return nil, errReportUsers
+	}
+	if !request.AllUsers {
+		return slices.Compact(slices.Sorted(slices.Values(request.Users))), nil
+	}
+	users, err := database.GetAllUsers()
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, u := range users {
+		names = append(names, u.Username)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// reportErrorStatus returns the response status of an invalid report request.
+func reportErrorStatus(err error) int {
+	if errors.Is(err, errReportUsers) {
+		return http.StatusUnauthorized
+	}
+	return http.StatusBadRequest
+}
+
+// userLocation returns the time zone of the named user.
+func userLocation(username string) *time.Location {
+	user, err := database.GetUser(username)
+	if err != nil {
+		return time.Local
+	}
+	return user.Location()
+}
+
+// reportProjects returns the sorted names of the projects covered by a report request.
+func reportProjects(request models.ReportRequest, billing billing) []string {
+	if request.Project != "" {
+		return []string{request.Project}
+	}
+	projects := []string{}
+	for _, project := range slices.Sorted(maps.Keys(billing.projects)) {
+		if request.Client != "" && billing.projects[project].Client != request.Client {
+			continue
+		}
+		projects = append(projects, project)
+	}
+	return projects
+}
+
+// tagReports returns the reports of records grouped by tag.  The records of each
+// project are rounded by the project's policy before being totalled.
+func tagReports(tagged map[string][]models.Record, billing billing, location *time.Location) []models.Report {
+	reports := []models.Report{}
+	for _, tag := range slices.Sorted(maps.Keys(tagged)) {
+		if displayRecord, ok := buildReport(tagged[tag], billing, models.Rounding{}, location); ok {
+			byProject := map[string]*reportTotals{}
+			for _, record := range tagged[tag] {
+				if byProject[record.Project] == nil {
+					byProject[record.Project] = &reportTotals{}
+				}
+				byProject[record.Project].add(record, billing)
+			}
+			totals := reportTotals{}
+			for project, projectTotals := range byProject {
+				projectTotals.round(billing.rounding(project))
+				totals.merge(*projectTotals)
+			}
+			totals.fill(&displayRecord)
+			displayRecord.Tag = tag
+			reports = append(reports, displayRecord)
+		}
+	}
+	return reports
+}
+
+// clientReports returns the subtotals of each client.
+func clientReports(clientTotals map[string]*reportTotals, billing billing) []models.Report {
+	reports := []models.Report{}
+	for _, name := range slices.Sorted(maps.Keys(clientTotals)) {
+		subtotal := models.Report{Client: name}
+		clientTotals[name].fill(&subtotal)
+		reports = append(reports, subtotal)
+	}
+	return reports
+}
+
+// userMatrix returns the time of each user on each project of the records.  Only
+// users and projects with time are included.  Durations are rounded per record.
+func userMatrix(records []models.Record, billing billing) *models.UserMatrix {
+	worked := map[string]map[string]time.Duration{}
+	users := map[string]time.Duration{}
+	for _, record := range records {
+		d := billing.rounding(record.Project).Record(record.Duration())
+		if d == 0 {
+			continue
+		}
+		if worked[record.Project] == nil {
+			worked[record.Project] = map[string]time.Duration{}
+		}
+		worked[record.Project][record.User] += d
+		users[record.User] += d
+	}
+	matrix := &models.UserMatrix{Users: slices.Sorted(maps.Keys(users))}
+	var total time.Duration
+	for _, project := range slices.Sorted(maps.Keys(worked)) {
+		row := models.MatrixRow{Project: project}
+		var projectTotal time.Duration
+		for _, user := range matrix.Users {
+			row.Cells = append(row.Cells, models.FmtDuration(worked[project][user]))
+			projectTotal += worked[project][user]
+		}
+		row.Total = models.FmtDuration(projectTotal)
+		matrix.Rows = append(matrix.Rows, row)
+		total += projectTotal
+	}
+	for _, user := range matrix.Users {
+		matrix.Totals = append(matrix.Totals, models.FmtDuration(users[user]))
+	}
+	matrix.Total = models.FmtDuration(total)
+	return matrix
+}
+
+// groupRecords totals records by group and, if subgroup is set, by subgroup within
+// each group.  Durations are rounded per record; per total rounding only applies to
+// the project totals of a report.  Records spanning several days, weeks or months of
+// a time based grouping count in each of them.
+func groupRecords(
+	records []models.Record,
+	group, subgroup string,
+	billing billing,
+	location *time.Location,
+) []models.ReportGroup {
+	totals := map[string]*reportTotals{}
+	members := map[string][]models.Record{}
+	for _, record := range records {
+		for _, part := range splitRecord(record, group, location) {
+			for _, key := range groupKeys(group, part, location) {
+				if totals[key] == nil {
+					totals[key] = &reportTotals{}
+				}
+				totals[key].add(part, billing)
+				members[key] = append(members[key], part)
+			}
+		}
+	}
+	groups := []models.ReportGroup{}
+	for _, key := range slices.Sorted(maps.Keys(totals)) {
+		g := models.ReportGroup{
+			Name:        key,
+			Total:       models.FmtDuration(totals[key].total),
+			Billable:    models.FmtDuration(totals[key].billable),
+			NonBillable: models.FmtDuration(totals[key].nonBillable),
+			Amount:      totals[key].money(),
+		}
+		if subgroup != "" {
+			g.Groups = groupRecords(members[key], subgroup, "", billing, location)
+		}
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// splitRecord returns the parts of a record falling in each day, week or month of a
+// time based grouping.  Each part is rounded as a record of its own.  Other
+// groupings do not split records.
+func splitRecord(record models.Record, group string, location *time.Location) []models.Record {
+	parts := []models.Record{}
+	for {
+		start := record.Start.In(location)
+		var next time.Time
+		switch group {
+		case models.GroupDay:
+			year, month, day := start.Date()
+			next = time.Date(year, month, day+1, 0, 0, 0, 0, location)
+		case models.GroupWeek:
+			next = models.PeriodEnd(models.PeriodWeek, start)
+		case models.GroupMonth:
+			next = models.PeriodEnd(models.PeriodMonth, start)
+		default:
+			return append(parts, record)
+		}
+		if !record.End.After(next) {
+			return append(parts, record)
+		}
+		part := record
+		part.End = next
+		parts = append(parts, part)
+		record.Start = next
+	}
+}
+
+// groupKeys returns the groups of a record, which sort in chronological order for
+// time based groupings.  A record belongs to a group for each of its tags.
+func groupKeys(group string, record models.Record, location *time.Location) []string {
+	start := record.Start.In(location)
+	switch group {
+	case models.GroupDay:
+		return []string{start.Format("2006-01-02")}
+	case models.GroupWeek:
+		year, week := start.ISOWeek()
+		return []string{fmt.Sprintf("%d-W%02d", year, week)}
+	case models.GroupMonth:
+		return []string{start.Format("2006-01")}
+	case models.GroupUser:
+		return []string{record.User}
+	case models.GroupTag:
+		if len(record.Tags) == 0 {
+			return []string{untagged}
+		}
+		return record.Tags
+	default:
+		return []string{record.Project}
+	}
+}
+
+// reportTotals accumulates the durations and billable value of records.
+type reportTotals struct {
+	total       time.Duration
+	billable    time.Duration
+	nonBillable time.Duration
+	amounts     map[string]int64 // billable value in cents by currency
+}
+
+// add adds a record to the totals, rounded if its project rounds each record.  The
+// record's billable flag, which defaults to that of its project when tracking starts,
+// determines whether the time is billed.
+func (t *reportTotals) add(record models.Record, billing billing) {
+	d := billing.rounding(record.Project).Record(record.Duration())
+	t.total += d
+	if !record.Billable {
+		t.nonBillable += d
+		return
+	}
+	t.billable += d
+	t.addAmount(billing.client(record.Project).Currency, models.Amount(d, billing.rate(record)))
+}
+
+// addAmount adds an amount in cents of the given currency to the totals.
+func (t *reportTotals) addAmount(currency string, amount int64) {
+	if t.amounts == nil {
+		t.amounts = map[string]int64{}
+	}
+	t.amounts[currency] += amount
+}
+
+// round applies per total rounding to the totals.  The amounts are adjusted in
+// proportion to the change in billable time.
+func (t *reportTotals) round(rounding models.Rounding) {
+	billable := rounding.Total(t.billable)
+	if t.billable != 0 && billable != t.billable {
+		for currency, amount := range t.amounts {
+			t.amounts[currency] = int64(math.Round(float64(amount) * float64(billable) / float64(t.billable)))
+		}
+	}
+	t.billable = billable
+	t.nonBillable = rounding.Total(t.nonBillable)
+	t.total = t.billable + t.nonBillable
+}
+
+// merge adds other totals to the totals.
+func (t *reportTotals) merge(other reportTotals) {
+	t.total += other.total
+	t.billable += other.billable
+	t.nonBillable += other.nonBillable
+	for currency, amount := range other.amounts {
+		t.addAmount(currency, amount)
+	}
+}
+
+// fill sets the formatted totals of a report.
+func (t *reportTotals) fill(report *models.Report) {
+	report.Total = models.FmtDuration(t.total)
+	report.Billable = models.FmtDuration(t.billable)
+	report.NonBillable = models.FmtDuration(t.nonBillable)
+	report.Amount = t.money()
+}
+
+// money returns the billable value of the totals for display.  Amounts in different
+// currencies cannot be added up, so each currency is shown separately.
+func (t *reportTotals) money() string {
+	if len(t.amounts) == 0 {
+		return models.FmtMoney(0)
+	}
+	amounts := []string{}
+	for _, currency := range slices.Sorted(maps.Keys(t.amounts)) {
+		amounts = append(amounts, strings.TrimSpace(models.FmtMoney(t.amounts[currency])+" "+currency))
+	}
+	return strings.Join(amounts, ", ")
+}
+
+// buildReport totals the given records, applying rounding to the totals, with the
+// times of the records in location.  It returns false if there is no time to report.
+func buildReport(
+	data []models.Record,
+	billing billing,
+	rounding models.Rounding,
+	location *time.Location,
+) (models.Report, bool) {
+	report := models.Report{}
+	totals := reportTotals{}
+	reported := false
+	for _, d := range data {
+		reported = reported || d.Duration() != 0
+		totals.add(d, billing)
+		report.Items = append(report.Items, models.ReportRecord{
+			ID:       d.ID,
+			Task:     d.Task,
+			Start:    d.Start.In(location),
+			End:      d.End.In(location),
+			Duration: billing.rounding(d.Project).Record(d.Duration()),
+			Note:     d.Note,
+			Tags:     d.Tags,
+			Billable: d.Billable,
+		})
+	}
+	totals.round(rounding)
+	totals.fill(&report)
+	report.Tasks = taskReports(report.Items)
+	return report, reported
+}
+
+// taskReports rolls up report items by task.  Nil is returned if no item has a task.
+func taskReports(items []models.ReportRecord) []models.TaskReport {
+	if !slices.ContainsFunc(items, func(i models.ReportRecord) bool { return i.Task != "" }) {
+		return nil
+	}
+	durations := map[string]time.Duration{}
+	byTask := map[string][]models.ReportRecord{}
+	for _, item := range items {
+		durations[item.Task] += item.Duration
+		byTask[item.Task] = append(byTask[item.Task], item)
+	}
+	tasks := []models.TaskReport{}
+	for _, task := range slices.Sorted(maps.Keys(durations)) {
+		tasks = append(tasks, models.TaskReport{
+			Task:  task,
+			Total: models.FmtDuration(durations[task]),
+			Items: byTask[task],
+		})
+	}
+	return tasks
 }
 
 func report(w http.ResponseWriter, r *http.Request) {
 	user := getRequestUser(r)
 	page := populatePage(user.Username)
+	if user.IsAdmin {
+		users, err := database.GetAllUsers()
+		if err != nil {
+			slog.Error("get users", "error", err)
+		}
+		for _, u := range users {
+			page.Users = append(page.Users, u.Username)
+		}
+		slices.Sort(page.Users)
+	}
 	render(w, "report", page)
 }
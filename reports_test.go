@@ -1,9 +1,11 @@
 package main
 
 import (
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -98,7 +100,136 @@ func TestGetReport(t *testing.T) {
 		should.BeNil(t, err)
 		should.BeEqual(t, w.Code, http.StatusOK)
 		should.ContainSubstring(t, string(body), "TimeTrace Report")
+		should.ContainSubstring(t, string(body), "release notes")
 	})
+
+	t.Run("billable", func(t *testing.T) {
+		deleteAllRecords()
+		deleteAllProjects()
+		_ = database.SaveProject(&models.Project{
+			ID:       uuid.New(),
+			Name:     "client",
+			Active:   true,
+			Billable: true,
+			Rates: []models.Rate{
+				{Amount: 10000, Effective: time.Now().Add(-time.Hour * 24 * 30)},
+			},
+		})
+		_ = database.SaveRecord(&models.Record{
+			ID:       uuid.New(),
+			Project:  "client",
+			User:     "test",
+			Start:    time.Now().Add(time.Hour * -3),
+			End:      time.Now().Add(time.Minute * -90),
+			Billable: true,
+		})
+		_ = database.SaveRecord(&models.Record{
+			ID:      uuid.New(),
+			Project: "client",
+			User:    "test",
+			Start:   time.Now().Add(time.Hour * -5),
+			End:     time.Now().Add(time.Hour * -4),
+		})
+		w := httptest.NewRecorder()
+		payload := bodyParams(
+			"start", time.Now().Add(-24*time.Hour).Format("2006-01-02"),
+			"end", time.Now().Format("2006-01-02"),
+		)
+		req := httptest.NewRequest(http.MethodPost, "/reports/", payload)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		router.ServeHTTP(w, req)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		body := w.Body.String()
+		should.ContainSubstring(t, body, "01:30 (1.50 Hours)")
+		should.ContainSubstring(t, body, "01:00 (1.00 Hours)")
+		should.ContainSubstring(t, body, "150.00")
+		createTestProjects()
+	})
+
+	t.Run("groupByTag", func(t *testing.T) {
+		deleteAllRecords()
+		createTestRecords()
+		w := httptest.NewRecorder()
+		payload := bodyParams(
+			"start", time.Now().Add(-24*time.Hour*14).Format("2006-01-02"),
+			"end", time.Now().Format("2006-01-02"),
+			"group", "tag",
+		)
+		req := httptest.NewRequest(http.MethodPost, "/reports/", payload)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		router.ServeHTTP(w, req)
+		body, err := io.ReadAll(w.Result().Body)
+		should.BeNil(t, err)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, string(body), "Tag dev")
+		should.ContainSubstring(t, string(body), "Tag meeting")
+		should.ContainSubstring(t, string(body), "Tag (no tag)")
+	})
+
+	t.Run("filterByTag", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		payload := bodyParams(
+			"start", time.Now().Add(-24*time.Hour*14).Format("2006-01-02"),
+			"end", time.Now().Format("2006-01-02"),
+			"tag", "meeting",
+		)
+		req := httptest.NewRequest(http.MethodPost, "/reports/", payload)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		router.ServeHTTP(w, req)
+		body, err := io.ReadAll(w.Result().Body)
+		should.BeNil(t, err)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, string(body), "Project golf")
+		if strings.Contains(string(body), "Project timetrace") {
+			t.Error("untagged project included in tag report")
+		}
+	})
+}
+
+func TestReportEmptyClient(t *testing.T) {
+	deleteAllUsers()
+	deleteAllRecords()
+	deleteAllProjects()
+	deleteAllClients()
+	createAdmin()
+	should.BeNil(t, database.SaveClient(&models.Client{Name: "acme", Currency: "CAD"}))
+	for _, name := range []string{"web", "ops"} {
+		should.BeNil(t, database.SaveProject(&models.Project{
+			ID: uuid.New(), Name: name, Client: "acme", Active: true,
+		}))
+	}
+	w := httptest.NewRecorder()
+	payload := bodyParams(
+		"start", time.Now().AddDate(0, 0, -1).Format("2006-01-02"),
+		"end", time.Now().Format("2006-01-02"),
+		"client", "acme",
+	)
+	req := httptest.NewRequest(http.MethodPost, "/reports/", payload)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(adminLogin())
+	router.ServeHTTP(w, req)
+	should.BeEqual(t, w.Code, http.StatusOK)
+	should.BeFalse(t, strings.Contains(w.Body.String(), "Client Totals"))
+	deleteAllClients()
+}
+
+func TestTaskReports(t *testing.T) {
+	now := time.Now()
+	items := []models.ReportRecord{
+		{Start: now.Add(-3 * time.Hour), End: now.Add(-2 * time.Hour), Duration: time.Hour},
+		{Task: "build", Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour), Duration: time.Hour},
+		{Task: "build", Start: now.Add(-time.Hour), End: now.Add(-30 * time.Minute), Duration: 30 * time.Minute},
+	}
+	should.BeNil(t, taskReports(items[:1]))
+	tasks := taskReports(items)
+	should.BeEqual(t, len(tasks), 2)
+	should.BeEqual(t, tasks[0].Task, "")
+	should.BeEqual(t, tasks[1].Task, "build")
+	should.BeEqual(t, tasks[1].Total, "01:30 (1.50 Hours)")
+	should.BeEqual(t, len(tasks[1].Items), 2)
 }
 
 func createTestRecords() {
@@ -130,6 +261,8 @@ func createTestRecords() {
 		User:    "test",
 		Start:   time.Now().Add(time.Hour * -24),
 		End:     time.Now().Add(time.Hour * -23),
+		Note:    "release notes",
+		Tags:    []string{"dev", "review"},
 	})
 	_ = database.SaveRecord(&models.Record{
 		ID:      uuid.New(),
@@ -144,6 +277,7 @@ func createTestRecords() {
 		User:    "test",
 		Start:   time.Now().Add(time.Hour * -24),
 		End:     time.Now().Add(time.Hour * -23),
+		Tags:    []string{"meeting"},
 	})
 	_ = database.SaveRecord(&models.Record{
 		ID:      uuid.New(),
@@ -160,3 +294,465 @@ func deleteAllRecords() {
 		_ = database.DeleteRecord(record.ID)
 	}
 }
+
+func TestReportRounding(t *testing.T) {
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)
+	billing := billing{
+		projects: map[string]models.Project{
+			"perRecord": {
+				Name:     "perRecord",
+				Rates:    []models.Rate{{Amount: 10000}},
+				Rounding: models.Rounding{Mode: models.RoundUp, Minutes: 15},
+			},
+			"perTotal": {Name: "perTotal", Client: "acme"},
+		},
+		clients: map[string]models.Client{
+			"acme": {
+				Name:     "acme",
+				Rate:     6000,
+				Rounding: models.Rounding{Mode: models.RoundNearest, Minutes: 30, PerTotal: true},
+			},
+		},
+	}
+	record := func(project string, minutes int) models.Record {
+		return models.Record{
+			Project:  project,
+			Start:    start,
+			End:      start.Add(time.Duration(minutes) * time.Minute),
+			Billable: true,
+		}
+	}
+	perRecord := []models.Record{record("perRecord", 10), record("perRecord", 20)}
+	perTotal := []models.Record{record("perTotal", 10), record("perTotal", 25)}
+	t.Run("perRecord", func(t *testing.T) {
+		report, ok := buildReport(perRecord, billing, billing.rounding("perRecord"), time.Local)
+		should.BeTrue(t, ok)
+		should.BeEqual(t, report.Total, models.FmtDuration(45*time.Minute))
+		should.BeEqual(t, report.Amount, "75.00")
+		should.BeEqual(t, report.Items[0].Duration, 15*time.Minute)
+	})
+	t.Run("perTotal", func(t *testing.T) {
+		report, ok := buildReport(perTotal, billing, billing.rounding("perTotal"), time.Local)
+		should.BeTrue(t, ok)
+		should.BeEqual(t, report.Total, models.FmtDuration(30*time.Minute))
+		should.BeEqual(t, report.Amount, "30.00")
+		should.BeEqual(t, report.Items[0].Duration, 10*time.Minute)
+	})
+	t.Run("location", func(t *testing.T) {
+		location := time.FixedZone("east", 16*3600)
+		report, ok := buildReport(perRecord, billing, billing.rounding("perRecord"), location)
+		should.BeTrue(t, ok)
+		should.BeEqual(t, report.Items[0].Start.Location(), location)
+		should.BeTrue(t, report.Items[0].Start.Equal(start))
+	})
+	t.Run("tag", func(t *testing.T) {
+		reports := tagReports(map[string][]models.Record{"dev": append(perRecord, perTotal...)}, billing, time.Local)
+		should.BeEqual(t, len(reports), 1)
+		should.BeEqual(t, reports[0].Total, models.FmtDuration(75*time.Minute))
+		should.BeEqual(t, reports[0].Amount, "105.00")
+	})
+	t.Run("invoice", func(t *testing.T) {
+		invoice := models.Invoice{}
+		buildInvoice(&invoice, append(perRecord, perTotal...), billing, false)
+		should.BeEqual(t, len(invoice.Lines), 2)
+		should.BeEqual(t, invoice.Lines[0].Duration, 45*time.Minute)
+		should.BeEqual(t, invoice.Lines[1].Duration, 30*time.Minute)
+		should.BeEqual(t, invoice.Total, int64(10500))
+	})
+	t.Run("roundedAway", func(t *testing.T) {
+		billing.projects["perRecord"] = models.Project{
+			Name:     "perRecord",
+			Rounding: models.Rounding{Mode: models.RoundDown, Minutes: 30},
+		}
+		report, ok := buildReport(perRecord, billing, billing.rounding("perRecord"), time.Local)
+		should.BeTrue(t, ok)
+		should.BeEqual(t, report.Total, models.FmtDuration(0))
+	})
+}
+
+func TestGroupRecords(t *testing.T) {
+	// Sunday of ISO week 41
+	sunday := time.Date(2026, 10, 11, 9, 0, 0, 0, time.UTC)
+	billing := billing{projects: map[string]models.Project{
+		"web": {Name: "web", Rates: []models.Rate{{Amount: 6000}}},
+		"ops": {Name: "ops"},
+	}}
+	record := func(project, user string, start time.Time, hours int, tags ...string) models.Record {
+		return models.Record{
+			Project:  project,
+			User:     user,
+			Start:    start,
+			End:      start.Add(time.Duration(hours) * time.Hour),
+			Tags:     tags,
+			Billable: project == "web",
+		}
+	}
+	records := []models.Record{
+		record("web", "alice", sunday, 2, "dev"),
+		record("ops", "alice", sunday.AddDate(0, 0, 1), 1),
+		record("web", "bob", sunday.AddDate(0, 0, 2), 3, "dev", "review"),
+		record("web", "alice", sunday.AddDate(0, 0, 30), 1),
+	}
+	t.Run("week", func(t *testing.T) {
+		groups := groupRecords(records, models.GroupWeek, models.GroupProject, billing, time.UTC)
+		should.BeEqual(t, len(groups), 3)
+		should.BeEqual(t, groups[0].Name, "2026-W41")
+		should.BeEqual(t, groups[1].Name, "2026-W42")
+		should.BeEqual(t, groups[1].Total, models.FmtDuration(4*time.Hour))
+		should.BeEqual(t, groups[1].Billable, models.FmtDuration(3*time.Hour))
+		should.BeEqual(t, groups[1].Amount, "180.00")
+		should.BeEqual(t, len(groups[1].Groups), 2)
+		should.BeEqual(t, groups[1].Groups[0].Name, "ops")
+		should.BeEqual(t, groups[1].Groups[1].Total, models.FmtDuration(3*time.Hour))
+		should.BeEqual(t, groups[2].Name, "2026-W46")
+	})
+	t.Run("month", func(t *testing.T) {
+		groups := groupRecords(records, models.GroupMonth, "", billing, time.UTC)
+		should.BeEqual(t, len(groups), 2)
+		should.BeEqual(t, groups[0].Name, "2026-10")
+		should.BeEqual(t, groups[0].Total, models.FmtDuration(6*time.Hour))
+		should.BeNil(t, groups[0].Groups)
+	})
+	t.Run("day", func(t *testing.T) {
+		groups := groupRecords(records, models.GroupDay, "", billing, time.FixedZone("east", 16*3600))
+		// 09:00 UTC is the next day sixteen hours east
+		should.BeEqual(t, groups[0].Name, "2026-10-12")
+	})
+	t.Run("userTag", func(t *testing.T) {
+		groups := groupRecords(records, models.GroupUser, models.GroupTag, billing, time.UTC)
+		should.BeEqual(t, len(groups), 2)
+		should.BeEqual(t, groups[0].Name, "alice")
+		should.BeEqual(t, groups[0].Groups[0].Name, untagged)
+		should.BeEqual(t, groups[0].Groups[1].Name, "dev")
+		should.BeEqual(t, groups[1].Name, "bob")
+		should.BeEqual(t, len(groups[1].Groups), 2)
+	})
+	t.Run("untaggedTag", func(t *testing.T) {
+		records := []models.Record{
+			record("web", "alice", sunday, 1, parseTags([]string{"(No Tag)"})...),
+			record("web", "alice", sunday, 2),
+		}
+		groups := groupRecords(records, models.GroupTag, "", billing, time.UTC)
+		should.BeEqual(t, len(groups), 2)
+		should.BeEqual(t, groups[0].Name, untagged)
+		should.BeEqual(t, groups[0].Total, models.FmtDuration(2*time.Hour))
+		should.BeEqual(t, groups[1].Name, "no tag")
+	})
+	t.Run("currencies", func(t *testing.T) {
+		billing.projects = map[string]models.Project{
+			"web": {Name: "web", Client: "acme", Rates: []models.Rate{{Amount: 6000}}},
+			"ops": {Name: "ops", Client: "globex", Rates: []models.Rate{{Amount: 5000}}},
+		}
+		billing.clients = map[string]models.Client{
+			"acme":   {Name: "acme", Currency: "CAD"},
+			"globex": {Name: "globex", Currency: "USD"},
+		}
+		records := []models.Record{
+			record("web", "alice", sunday, 2),
+			record("ops", "alice", sunday, 1),
+		}
+		records[1].Billable = true
+		groups := groupRecords(records, models.GroupUser, models.GroupProject, billing, time.UTC)
+		should.BeEqual(t, len(groups), 1)
+		should.BeEqual(t, groups[0].Amount, "120.00 CAD, 50.00 USD")
+		should.BeEqual(t, groups[0].Groups[0].Amount, "50.00 USD")
+		should.BeEqual(t, groups[0].Groups[1].Amount, "120.00 CAD")
+	})
+}
+
+func TestGroupedReport(t *testing.T) {
+	deleteAllUsers()
+	deleteAllRecords()
+	deleteAllProjects()
+	createTestProjects()
+	user := models.User{Username: "test", Password: "testing"}
+	should.BeNil(t, createTestUser(user))
+	cookie := testLogin(user)
+	createTestRecords()
+	request := func(params ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		params = append(params,
+			"start", time.Now().AddDate(0, 0, -14).Format("2006-01-02"),
+			"end", time.Now().Format("2006-01-02"))
+		req := httptest.NewRequest(http.MethodPost, "/reports/", bodyParams(params...))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	t.Run("dayProject", func(t *testing.T) {
+		w := request("group", "day", "subgroup", "project")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		body := w.Body.String()
+		should.ContainSubstring(t, body, "Totals by day and project")
+		should.ContainSubstring(t, body, "<td><b>"+time.Now().Format("2006-01-02")+"</b></td>")
+		should.ContainSubstring(t, body, `<tr class="subgroup">`)
+		should.BeFalse(t, strings.Contains(body, "Project timetrace"))
+	})
+	t.Run("projectWeek", func(t *testing.T) {
+		w := request("group", "project", "subgroup", "week")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		year, week := time.Now().ISOWeek()
+		should.ContainSubstring(t, w.Body.String(), fmt.Sprintf("%d-W%02d", year, week))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		w := request("group", "fortnight")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "invalid group")
+		w = request("group", "day", "subgroup", "decade")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "invalid subgroup")
+	})
+}
+
+func TestTeamReport(t *testing.T) {
+	deleteAllUsers()
+	deleteAllRecords()
+	deleteAllProjects()
+	createTestProjects()
+	createAdmin()
+	user := models.User{Username: "test", Password: "testing"}
+	should.BeNil(t, createTestUser(user))
+	createTestRecords()
+	_ = database.SaveRecord(&models.Record{
+		ID:      uuid.New(),
+		Project: "test",
+		User:    "admin",
+		Start:   time.Now().Add(-3 * time.Hour),
+		End:     time.Now().Add(-time.Hour),
+	})
+	request := func(cookie *http.Cookie, path string, params url.Values) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		params.Set("start", time.Now().AddDate(0, 0, -7).Format("2006-01-02"))
+		params.Set("end", time.Now().Format("2006-01-02"))
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(params.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if path != "/reports/" {
+			req = httptest.NewRequest(http.MethodGet, path+"?"+params.Encode(), nil)
+		}
+		req.AddCookie(cookie)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	t.Run("form", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/reports/", nil)
+		req.AddCookie(adminLogin())
+		router.ServeHTTP(w, req)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), `<option value="test">test</option>`)
+		should.ContainSubstring(t, w.Body.String(), `name="allusers"`)
+	})
+	t.Run("allUsers", func(t *testing.T) {
+		w := request(adminLogin(), "/reports/", url.Values{"allusers": {"on"}})
+		should.BeEqual(t, w.Code, http.StatusOK)
+		body := w.Body.String()
+		should.ContainSubstring(t, body, "Users by Project")
+		should.ContainSubstring(t, body, "<td>admin</td>")
+		should.ContainSubstring(t, body, "<td>test</td>")
+		should.ContainSubstring(t, body, "Totals by user and project")
+		should.ContainSubstring(t, body, "&allusers=true")
+		should.BeFalse(t, strings.Contains(body, "Flextime"))
+	})
+	t.Run("selected", func(t *testing.T) {
+		w := request(adminLogin(), "/reports/", url.Values{"users": {"test"}})
+		should.BeEqual(t, w.Code, http.StatusOK)
+		body := w.Body.String()
+		should.ContainSubstring(t, body, "Project timetrace")
+		should.BeFalse(t, strings.Contains(body, "Project test"))
+		should.ContainSubstring(t, body, "&users=test")
+	})
+	t.Run("export", func(t *testing.T) {
+		w := request(adminLogin(), "/reports/csv", url.Values{"users": {"test", "admin"}})
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "test,,admin,")
+		should.ContainSubstring(t, w.Body.String(), "timetrace,,test,")
+	})
+	t.Run("self", func(t *testing.T) {
+		w := request(testLogin(user), "/reports/", url.Values{"users": {"test"}})
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.BeFalse(t, strings.Contains(w.Body.String(), "Users by Project"))
+	})
+	t.Run("notAdmin", func(t *testing.T) {
+		w := request(testLogin(user), "/reports/", url.Values{"users": {"admin"}})
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+		w = request(testLogin(user), "/reports/xlsx", url.Values{"allusers": {"on"}})
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+	})
+}
+
+func TestUserMatrix(t *testing.T) {
+	start := time.Now()
+	records := []models.Record{
+		{Project: "web", User: "bob", Start: start, End: start.Add(2 * time.Hour)},
+		{Project: "web", User: "alice", Start: start, End: start.Add(time.Hour)},
+		{Project: "ops", User: "bob", Start: start, End: start.Add(30 * time.Minute)},
+		{Project: "idle", User: "carol", Start: start, End: start},
+	}
+	matrix := userMatrix(records, billing{})
+	should.BeEqual(t, matrix.Users, []string{"alice", "bob"})
+	should.BeEqual(t, len(matrix.Rows), 2)
+	should.BeEqual(t, matrix.Rows[0].Project, "ops")
+	should.BeEqual(t, matrix.Rows[0].Cells, []string{models.FmtDuration(0), models.FmtDuration(30 * time.Minute)})
+	should.BeEqual(t, matrix.Rows[1].Total, models.FmtDuration(3*time.Hour))
+	should.BeEqual(t, matrix.Totals, []string{models.FmtDuration(time.Hour), models.FmtDuration(150 * time.Minute)})
+	should.BeEqual(t, matrix.Total, models.FmtDuration(210*time.Minute))
+}
+
+func TestSplitRecord(t *testing.T) {
+	// Sunday evening to Monday morning, crossing a day and a week
+	start := time.Date(2026, 5, 31, 22, 0, 0, 0, time.UTC)
+	record := models.Record{Project: "web", Start: start, End: start.Add(4 * time.Hour)}
+	parts := splitRecord(record, models.GroupDay, time.UTC)
+	should.BeEqual(t, len(parts), 2)
+	should.BeEqual(t, parts[0].Duration(), 2*time.Hour)
+	should.BeEqual(t, parts[1].Start, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+	should.BeEqual(t, len(splitRecord(record, models.GroupWeek, time.UTC)), 2)
+	should.BeEqual(t, len(splitRecord(record, models.GroupMonth, time.UTC)), 2)
+	should.BeEqual(t, len(splitRecord(record, models.GroupProject, time.UTC)), 1)
+	// the same hours fall on one day two hours east
+	should.BeEqual(t, len(splitRecord(record, models.GroupDay, time.FixedZone("east", 2*3600))), 1)
+	groups := groupRecords([]models.Record{record}, models.GroupDay, "", billing{}, time.UTC)
+	should.BeEqual(t, len(groups), 2)
+	should.BeEqual(t, groups[0].Name, "2026-05-31")
+	should.BeEqual(t, groups[1].Total, models.FmtDuration(2*time.Hour))
+}
+
+func TestStraddlingRecords(t *testing.T) {
+	deleteAllUsers()
+	deleteAllRecords()
+	deleteAllProjects()
+	createTestProjects()
+	user := models.User{Username: "test", Password: "testing"}
+	should.BeNil(t, createTestUser(user))
+	today := startOfDay(time.Now())
+	// started late yesterday, ending an hour into today
+	should.BeNil(t, database.SaveRecord(&models.Record{
+		ID:      uuid.New(),
+		Project: "test",
+		User:    "test",
+		Start:   today.Add(-2 * time.Hour),
+		End:     today.Add(time.Hour),
+	}))
+	t.Run("status", func(t *testing.T) {
+		status, err := getStatus("test")
+		should.BeNil(t, err)
+		should.BeEqual(t, status.DailyTotal, models.FmtDuration(time.Hour))
+	})
+	t.Run("report", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		payload := bodyParams(
+			"start", today.Format("2006-01-02"),
+			"end", today.Format("2006-01-02"),
+			"project", "test",
+		)
+		req := httptest.NewRequest(http.MethodPost, "/reports/", payload)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(testLogin(user))
+		router.ServeHTTP(w, req)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "<h2>"+models.FmtDuration(time.Hour)+"</h2>")
+	})
+	t.Run("yesterday", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		payload := bodyParams(
+			"start", today.AddDate(0, 0, -1).Format("2006-01-02"),
+			"end", today.AddDate(0, 0, -1).Format("2006-01-02"),
+			"project", "test",
+		)
+		req := httptest.NewRequest(http.MethodPost, "/reports/", payload)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(testLogin(user))
+		router.ServeHTTP(w, req)
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "<h2>"+models.FmtDuration(2*time.Hour)+"</h2>")
+	})
+}
+
+func TestComparePeriod(t *testing.T) {
+	day := func(s string) time.Time {
+		d, err := time.ParseInLocation("2006-01-02", s, time.UTC)
+		should.BeNil(t, err)
+		return d
+	}
+	dbRequest := models.DatabaseReportRequest{Start: day("2026-03-09"), End: day("2026-03-15")}
+	start, end, err := comparePeriod(models.ReportRequest{Compare: models.ComparePrevious}, dbRequest, time.UTC)
+	should.BeNil(t, err)
+	should.BeEqual(t, start, day("2026-03-02"))
+	should.BeEqual(t, end, day("2026-03-08"))
+	request := models.ReportRequest{
+		Compare:      models.CompareRange,
+		CompareStart: "2025-03-09",
+		CompareEnd:   "2025-03-15",
+	}
+	start, end, err = comparePeriod(request, dbRequest, time.UTC)
+	should.BeNil(t, err)
+	should.BeEqual(t, start, day("2025-03-09"))
+	should.BeEqual(t, end, day("2025-03-15"))
+	request.CompareEnd = "2025-03-01"
+	_, _, err = comparePeriod(request, dbRequest, time.UTC)
+	should.NotBeNil(t, err)
+	_, _, err = comparePeriod(models.ReportRequest{Compare: "sprint"}, dbRequest, time.UTC)
+	should.NotBeNil(t, err)
+}
+
+func TestReportComparison(t *testing.T) {
+	deleteAllUsers()
+	deleteAllRecords()
+	deleteAllProjects()
+	createTestProjects()
+	user := models.User{Username: "test", Password: "testing"}
+	should.BeNil(t, createTestUser(user))
+	today := startOfDay(time.Now())
+	for _, record := range []models.Record{
+		{Project: "test", Start: today.Add(time.Hour), End: today.Add(3 * time.Hour)},
+		{Project: "test", Start: today.AddDate(0, 0, -7).Add(time.Hour), End: today.AddDate(0, 0, -7).Add(2 * time.Hour)},
+		{Project: "test2", Start: today.AddDate(0, 0, -8).Add(time.Hour), End: today.AddDate(0, 0, -8).Add(2 * time.Hour)},
+	} {
+		record.ID = uuid.New()
+		record.User = "test"
+		should.BeNil(t, database.SaveRecord(&record))
+	}
+	request := func(params ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		params = append(params,
+			"start", today.AddDate(0, 0, -6).Format("2006-01-02"),
+			"end", today.Format("2006-01-02"))
+		req := httptest.NewRequest(http.MethodPost, "/reports/", bodyParams(params...))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(testLogin(user))
+		router.ServeHTTP(w, req)
+		return w
+	}
+	t.Run("previous", func(t *testing.T) {
+		w := request("compare", "previous")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		body := w.Body.String()
+		should.ContainSubstring(t, body, "Compared with "+today.AddDate(0, 0, -13).Format("2006-01-02")+
+			" - "+today.AddDate(0, 0, -7).Format("2006-01-02"))
+		should.ContainSubstring(t, body, "<td>&#43;1.00</td>")
+		should.ContainSubstring(t, body, "<td>&#43;100%</td>")
+		// test2 only has time in the previous period
+		should.ContainSubstring(t, body, "<td>-1.00</td>")
+		should.ContainSubstring(t, body, "<td>-100%</td>")
+		should.ContainSubstring(t, body, "<td><b>&#43;0.00</b></td>")
+	})
+	t.Run("range", func(t *testing.T) {
+		w := request("compare", "range",
+			"compareStart", today.AddDate(0, 0, -7).Format("2006-01-02"),
+			"compareEnd", today.AddDate(0, 0, -7).Format("2006-01-02"))
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "<td>&#43;1.00</td>")
+		should.BeFalse(t, strings.Contains(w.Body.String(), "<td>-1.00</td>"))
+	})
+	t.Run("none", func(t *testing.T) {
+		w := request()
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.BeFalse(t, strings.Contains(w.Body.String(), "Compared with"))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		w := request("compare", "range", "compareStart", "yesterday")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "invalid comparison start")
+	})
+}
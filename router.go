@@ -48,6 +48,15 @@ func setupRouter() *mux.Router {
 	router.Post("/login", login)
 	router.Get("/logout/", logout)
 	router.Get("/{$}", displayMain)
+	router.Get("/feed/{token}", calendarFeed)
+	router.All("/.well-known/caldav", davWellKnown)
+
+	dav := router.Group("/dav", davAuth)
+	dav.CustomMethod(http.MethodOptions, "/", davOptions)
+	dav.CustomMethod("PROPFIND", "/{$}", davPrincipal)
+	dav.CustomMethod("PROPFIND", "/{user}/{$}", davCalendar)
+	dav.CustomMethod("REPORT", "/{user}/{$}", davReport)
+	dav.Get("/{user}/{event}", davEvent)
 
 	status := router.Group("/status", auth)
 	status.Get("/{$}", displayStatus)
@@ -57,6 +66,8 @@ func setupRouter() *mux.Router {
 	users.Get("/register/", register)
 	users.Post("/register/", registerUser)
 	users.Post("/{name}", editUser)
+	users.Get("/schedule/{name}", getSchedule)
+	users.Post("/schedule/{name}", saveSchedule)
 	users.Delete("/{name}", deleteUser)
 	users.Get("/{name}", getUser)
 
@@ -66,15 +77,70 @@ func setupRouter() *mux.Router {
 	projects.Post("/{$}", addProject)
 	projects.Post("/stop/", stop)
 	projects.Post("/start/{name}", start)
+	projects.Post("/start/{name}/{task}", start)
+	projects.Get("/edit/{name}", displayEditProject)
+	projects.Post("/edit/{name}", editProject)
+	projects.Post("/rates/{name}", addRate)
+	projects.Post("/favorite/{name}", toggleFavorite)
+	projects.Get("/members/{name}", getMembers)
+	projects.Post("/members/{name}", addMember)
+	projects.Delete("/members/{name}/{user}", removeMember)
+	projects.Post("/archive/{name}", archiveProject)
+	projects.Post("/reactivate/{name}", reactivateProject)
+	projects.Delete("/{name}", deleteProject)
+	projects.Post("/tasks/{name}", addTask)
+	projects.Delete("/tasks/{name}/{task}", deleteTask)
 
 	reports := router.Group("/reports", auth)
 	reports.Get("/{$}", report)
 	reports.Post("/{$}", getReport)
+	reports.Get("/csv", exportCSV)
+	reports.Get("/xlsx", exportXLSX)
+	reports.Get("/ics", exportICS)
 
 	records := router.Group("/records", auth)
 	records.Get("/{id}", getRecord)
 	records.Post("/{id}", editRecord)
 
+	clients := router.Group("/clients", auth)
+	clients.Get("/{$}", getClients)
+	clients.Get("/new/", displayClientForm)
+	clients.Post("/{$}", saveClient)
+	clients.Get("/{name}", getClient)
+	clients.Delete("/{name}", deleteClient)
+
+	invoices := router.Group("/invoices", auth)
+	invoices.Get("/{$}", getInvoices)
+	invoices.Post("/{$}", createInvoice)
+	invoices.Get("/{id}", getInvoice)
+	invoices.Post("/void/{id}", voidInvoice)
+
+	timesheet := router.Group("/timesheet", auth)
+	timesheet.Get("/{$}", getTimesheet)
+	timesheet.Post("/{$}", addTimesheetHours)
+	timesheet.Get("/records/", getTimesheetRecords)
+
+	goals := router.Group("/goals", auth)
+	goals.Get("/{$}", getGoals)
+	goals.Post("/{$}", saveGoal)
+	goals.Delete("/{$}", deleteGoal)
+
+	absences := router.Group("/absences", auth)
+	absences.Get("/{$}", getAbsences)
+	absences.Post("/{$}", addAbsence)
+	absences.Delete("/{id}", deleteAbsence)
+
+	holidays := router.Group("/holidays", auth)
+	holidays.Get("/{$}", getHolidays)
+	holidays.Post("/{$}", addHoliday)
+	holidays.Post("/import/", importHolidays)
+	holidays.Delete("/{name}", deleteHolidayCalendar)
+
+	calendar := router.Group("/calendar", auth)
+	calendar.Get("/{$}", getCalendar)
+	calendar.Get("/feed/", getFeed)
+	calendar.Post("/feed/", newFeedToken)
+
 	configuration := router.Group("/config", auth)
 	configuration.Get("/{$}", configOld)
 	configuration.Post("/{$}", setConfig)
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+)
+
+// untagged is the report group label for records without tags.  It is parenthesized
+// so it cannot collide with a tag, as parseTags strips parentheses around tags.
+const untagged = "(no tag)"
+
+// parseTags normalizes tag form values.  Each value may contain a comma separated
+// list of tags; the result is lower case, without surrounding parentheses,
+// de-duplicated and sorted.
+func parseTags(values []string) []string {
+	tags := []string{}
+	for _, value := range values {
+		for tag := range strings.SplitSeq(value, ",") {
+			tag = strings.ToLower(strings.TrimSpace(strings.Trim(strings.TrimSpace(tag), "()")))
+			if tag == "" || slices.Contains(tags, tag) {
+				continue
+			}
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+	return tags
+}
+
+// registerTags adds any tags not already in the tag registry.
+func registerTags(tags []string) error {
+	for _, name := range tags {
+		if _, err := database.GetTag(name); err == nil {
+			continue
+		}
+		if err := database.SaveTag(&models.Tag{Name: name, Updated: time.Now()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagNames returns the names of all registered tags.
+func tagNames() []string {
+	names := []string{}
+	tags, err := database.GetAllTags()
+	if err != nil {
+		slog.Error("get tags", "error", err)
+		return names
+	}
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
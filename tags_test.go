@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/database"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "empty",
+			args: []string{"", " "},
+			want: []string{},
+		},
+		{
+			name: "checkboxes",
+			args: []string{"review", "meeting"},
+			want: []string{"meeting", "review"},
+		},
+		{
+			name: "commaSeparated",
+			args: []string{"Dev, support,,", "dev"},
+			want: []string{"dev", "support"},
+		},
+		{
+			name: "parenthesized",
+			args: []string{"(No Tag)", "( review )", "()"},
+			want: []string{"no tag", "review"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			should.BeEqual(t, parseTags(tt.args), tt.want)
+		})
+	}
+}
+
+func TestRegisterTags(t *testing.T) {
+	tags, err := database.GetAllTags()
+	should.BeNil(t, err)
+	for _, tag := range tags {
+		should.BeNil(t, database.DeleteTag(tag.Name))
+	}
+	should.BeNil(t, registerTags([]string{"dev", "meeting"}))
+	should.BeNil(t, registerTags([]string{"dev"}))
+	should.BeEqual(t, tagNames(), []string{"dev", "meeting"})
+}
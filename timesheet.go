@@ -0,0 +1,284 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+// timesheetDayStart is the time of day from which hours entered in a timesheet are
+// recorded, unless the user has already worked later that day.
+const timesheetDayStart = 9 * time.Hour
+
+func getTimesheet(w http.ResponseWriter, r *http.Request) {
+	user, err := database.GetUser(getRequestUser(r).Username)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	period, date, err := parseTimesheetPeriod(r, user.Location())
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	renderTimesheet(w, user, period, date)
+}
+
+// getTimesheetRecords displays the records of a timesheet cell.
+func getTimesheetRecords(w http.ResponseWriter, r *http.Request) {
+	user, err := database.GetUser(getRequestUser(r).Username)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	period, date, err := parseTimesheetPeriod(r, user.Location())
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	project := r.FormValue("project")
+	records, err := timesheetCellRecords(user.Username, project, date)
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	cell := models.TimesheetRecords{Project: project, Date: date, Period: period}
+	for _, record := range records {
+		cell.Items = append(cell.Items, models.ReportRecord{
+			ID:       record.ID,
+			Task:     record.Task,
+			Start:    record.Start.In(date.Location()),
+			End:      record.End.In(date.Location()),
+			Duration: record.Duration(),
+			Note:     record.Note,
+			Tags:     record.Tags,
+			Billable: record.Billable,
+		})
+	}
+	render(w, "timesheetRecords", cell)
+}
+
+// addTimesheetHours records the hours entered in an empty timesheet cell.  The record
+// starts at timesheetDayStart or at the end of the user's last record that day, and
+// is validated like an edited record.
+func addTimesheetHours(w http.ResponseWriter, r *http.Request) {
+	user, err := database.GetUser(getRequestUser(r).Username)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	period, date, err := parseTimesheetPeriod(r, user.Location())
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	hours, err := parseHours(r.FormValue("hours"))
+	if err != nil || hours <= 0 || hours > 24*time.Hour {
+		processError(w, http.StatusBadRequest, "invalid hours")
+		return
+	}
+	project, err := database.GetProject(r.FormValue("project"))
+	if err != nil {
+		processError(w, http.StatusBadRequest, "error reading project "+err.Error())
+		return
+	}
+	if !canAccessProject(user, project) {
+		processError(w, http.StatusUnauthorized, "you are not a member of this project")
+		return
+	}
+	if !project.Active {
+		processError(w, http.StatusBadRequest, "project is not active")
+		return
+	}
+	records, err := database.GetAllRecordsForUser(user.Username)
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	record, err := timesheetRecord(user.Username, project, date, hours, records)
+	if err != nil {
+		processError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	edit := models.EditRecord{}
+	if _, err := validateRecord(record, &edit); err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if edit.HasErrors() {
+		processError(w, http.StatusBadRequest, strings.Join(slices.Sorted(maps.Values(edit.Errors)), "; "))
+		return
+	}
+	if err := database.SaveRecord(&record); err != nil {
+		processError(w, http.StatusInternalServerError, "failed to save record "+err.Error())
+		return
+	}
+	slog.Info("timesheet hours added", "project", project.Name, "date", date, "hours", hours)
+	checkBudget(project.Name, alertBudget)
+	renderTimesheet(w, user, period, date)
+}
+
+// timesheetRecord returns a record of hours worked on a project on the day of date.
+// The day must not already have records for the project.  The record is not
+// validated against the user's other records.
+func timesheetRecord(
+	user string,
+	project models.Project,
+	date time.Time,
+	hours time.Duration,
+	records []models.Record,
+) (models.Record, error) {
+	start := date.Add(timesheetDayStart)
+	next := date.AddDate(0, 0, 1)
+	for _, record := range records {
+		if record.End.IsZero() {
+			record.End = time.Now()
+		}
+		// records started the day before may run into the day
+		if !record.Clip(date, next) {
+			continue
+		}
+		if record.Project == project.Name {
+			return models.Record{}, errors.New("the day already has records for this project")
+		}
+		if record.End.After(start) {
+			start = record.End
+		}
+	}
+	return models.Record{
+		ID:       uuid.New(),
+		Project:  project.Name,
+		User:     user,
+		Start:    start,
+		End:      start.Add(hours),
+		Billable: project.Billable,
+	}, nil
+}
+
+func renderTimesheet(w http.ResponseWriter, user models.User, period string, date time.Time) {
+	records, err := database.GetAllRecordsForUser(user.Username)
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	projects, err := database.GetAllProjects()
+	if err != nil {
+		processError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	render(w, "timesheet", buildTimesheet(period, date, user, records, projects, time.Now()))
+}
+
+// parseTimesheetPeriod returns the period, a week unless a month is requested, and the
+// date of a timesheet request.  The date defaults to today.
+func parseTimesheetPeriod(r *http.Request, location *time.Location) (string, time.Time, error) {
+	period := models.PeriodWeek
+	if r.FormValue("period") == models.PeriodMonth {
+		period = models.PeriodMonth
+	}
+	date := startOfDay(time.Now().In(location))
+	if value := r.FormValue("date"); value != "" {
+		var err error
+		date, err = time.ParseInLocation("2006-01-02", value, location)
+		if err != nil {
+			return period, date, err
+		}
+	}
+	return period, date, nil
+}
+
+// timesheetCellRecords returns the records of the user for a project on the day of
+// date, clipped to the day as in the timesheet and sorted by start time.  Records
+// still being tracked end now.
+func timesheetCellRecords(user, project string, date time.Time) ([]models.Record, error) {
+	records, err := database.GetAllRecordsForUser(user)
+	if err != nil {
+		return nil, err
+	}
+	next := date.AddDate(0, 0, 1)
+	now := time.Now()
+	day := []models.Record{}
+	for _, record := range records {
+		if record.End.IsZero() {
+			record.End = now
+		}
+		if record.Project == project && record.Clip(date, next) {
+			day = append(day, record)
+		}
+	}
+	slices.SortFunc(day, func(a, b models.Record) int { return a.Start.Compare(b.Start) })
+	return day, nil
+}
+
+// buildTimesheet returns the timesheet of the period containing date.  It has a row
+// for each project with time in the period and for each active project the user may
+// record time on.  Records spanning midnight count on each day they cover and records
+// still being tracked are counted up to now.
+func buildTimesheet(
+	period string,
+	date time.Time,
+	user models.User,
+	records []models.Record,
+	projects []models.Project,
+	now time.Time,
+) models.Timesheet {
+	start := models.PeriodStart(period, date)
+	end := models.PeriodEnd(period, date)
+	sheet := models.Timesheet{
+		Period:   period,
+		Start:    start,
+		Previous: models.PeriodStart(period, start.AddDate(0, 0, -1)).Format("2006-01-02"),
+		Next:     end.Format("2006-01-02"),
+	}
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		sheet.Days = append(sheet.Days, day)
+	}
+	worked := map[string]map[string]time.Duration{}
+	for _, record := range records {
+		if record.End.IsZero() {
+			record.End = now
+		}
+		if !record.Clip(start, end) {
+			continue
+		}
+		if worked[record.Project] == nil {
+			worked[record.Project] = map[string]time.Duration{}
+		}
+		for _, part := range splitRecord(record, models.GroupDay, date.Location()) {
+			worked[part.Project][part.Start.In(date.Location()).Format("2006-01-02")] += part.Duration()
+		}
+	}
+	sortProjects(projects)
+	sheet.Totals = make([]models.TimesheetCell, len(sheet.Days))
+	for i, day := range sheet.Days {
+		sheet.Totals[i].Date = day
+	}
+	for _, project := range projects {
+		editable := project.Active && canAccessProject(user, project)
+		if worked[project.Name] == nil && !editable {
+			continue
+		}
+		row := models.TimesheetRow{Project: project.Name, Color: project.Color, Editable: editable}
+		for i, day := range sheet.Days {
+			cell := models.TimesheetCell{
+				Project: project.Name,
+				Date:    day,
+				Worked:  worked[project.Name][day.Format("2006-01-02")],
+			}
+			row.Cells = append(row.Cells, cell)
+			row.Total += cell.Worked
+			sheet.Totals[i].Worked += cell.Worked
+		}
+		sheet.Total += row.Total
+		sheet.Rows = append(sheet.Rows, row)
+	}
+	return sheet
+}
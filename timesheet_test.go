@@ -0,0 +1,197 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+	"github.com/google/uuid"
+)
+
+func TestBuildTimesheet(t *testing.T) {
+	// Wednesday
+	date := time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local)
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	user := models.User{Username: "test"}
+	projects := []models.Project{
+		{Name: "web", Active: true, Order: 2},
+		{Name: "admin", Active: true, Order: 1},
+		{Name: "closed"},
+		{Name: "private", Active: true, Members: []models.Member{{User: "other", Role: models.RoleOwner}}},
+	}
+	records := []models.Record{
+		{Project: "web", Start: monday.Add(9 * time.Hour), End: monday.Add(11 * time.Hour)},
+		{Project: "web", Start: monday.Add(13 * time.Hour), End: monday.Add(14 * time.Hour)},
+		{Project: "closed", Start: date.Add(9 * time.Hour), End: date.Add(10 * time.Hour)},
+		{Project: "web", Start: monday.AddDate(0, 0, -1), End: monday.AddDate(0, 0, -1).Add(time.Hour)},
+		{Project: "admin", Start: date.Add(15 * time.Hour)},
+	}
+	now := date.Add(15*time.Hour + 30*time.Minute)
+	sheet := buildTimesheet(models.PeriodWeek, date, user, records, projects, now)
+	should.BeEqual(t, sheet.Start, monday)
+	should.BeEqual(t, sheet.Previous, "2026-10-05")
+	should.BeEqual(t, sheet.Next, "2026-10-19")
+	should.BeEqual(t, len(sheet.Days), 7)
+	should.BeEqual(t, len(sheet.Rows), 3)
+	// sorted by order, the private project is hidden
+	should.BeEqual(t, sheet.Rows[0].Project, "closed")
+	should.BeFalse(t, sheet.Rows[0].Editable)
+	should.BeEqual(t, sheet.Rows[1].Project, "admin")
+	should.BeEqual(t, sheet.Rows[1].Cells[2].Worked, 30*time.Minute)
+	should.BeTrue(t, sheet.Rows[1].Editable)
+	should.BeEqual(t, sheet.Rows[2].Project, "web")
+	should.BeEqual(t, sheet.Rows[2].Cells[0].Worked, 3*time.Hour)
+	should.BeEqual(t, sheet.Rows[2].Total, 3*time.Hour)
+	should.BeEqual(t, sheet.Totals[2].Worked, 90*time.Minute)
+	should.BeEqual(t, sheet.Total, 270*time.Minute)
+	should.BeEqual(t, sheet.Totals[2].FmtWorked(), "01:30")
+	should.BeEqual(t, sheet.Totals[3].FmtWorked(), "")
+
+	month := buildTimesheet(models.PeriodMonth, date, user, records, projects, now)
+	should.BeEqual(t, len(month.Days), 31)
+	should.BeEqual(t, month.Previous, "2026-09-01")
+	should.BeEqual(t, month.Total, 330*time.Minute)
+
+	overnight := []models.Record{
+		{Project: "web", Start: monday.Add(-time.Hour), End: monday.Add(time.Hour)},
+		{Project: "web", Start: date.Add(-2 * time.Hour), End: date.Add(3 * time.Hour)},
+	}
+	sheet = buildTimesheet(models.PeriodWeek, date, user, overnight, projects, now)
+	should.BeEqual(t, sheet.Rows[1].Project, "web")
+	should.BeEqual(t, sheet.Rows[1].Cells[0].Worked, time.Hour)
+	should.BeEqual(t, sheet.Rows[1].Cells[1].Worked, 2*time.Hour)
+	should.BeEqual(t, sheet.Rows[1].Cells[2].Worked, 3*time.Hour)
+	should.BeEqual(t, sheet.Total, 6*time.Hour)
+}
+
+func TestTimesheetRecord(t *testing.T) {
+	date := time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local)
+	project := models.Project{Name: "web", Billable: true}
+	record, err := timesheetRecord("test", project, date, 2*time.Hour, nil)
+	should.BeNil(t, err)
+	should.BeEqual(t, record.Start, date.Add(9*time.Hour))
+	should.BeEqual(t, record.End, date.Add(11*time.Hour))
+	should.BeTrue(t, record.Billable)
+	records := []models.Record{{Project: "other", Start: date.Add(8 * time.Hour), End: date.Add(12 * time.Hour)}}
+	record, err = timesheetRecord("test", project, date, 2*time.Hour, records)
+	should.BeNil(t, err)
+	should.BeEqual(t, record.Start, date.Add(12*time.Hour))
+	// a record started the day before runs until 10:00
+	overnight := []models.Record{{Project: "other", Start: date.Add(-2 * time.Hour), End: date.Add(10 * time.Hour)}}
+	record, err = timesheetRecord("test", project, date, 2*time.Hour, overnight)
+	should.BeNil(t, err)
+	should.BeEqual(t, record.Start, date.Add(10*time.Hour))
+	records = append(records, models.Record{Project: "web", Start: date.Add(time.Hour), End: date.Add(2 * time.Hour)})
+	_, err = timesheetRecord("test", project, date, time.Hour, records)
+	should.NotBeNil(t, err)
+}
+
+func TestTimesheet(t *testing.T) {
+	deleteAllUsers()
+	deleteAllRecords()
+	deleteAllProjects()
+	createTestProjects()
+	user := models.User{Username: "test", Password: "testing"}
+	should.BeNil(t, createTestUser(user))
+	cookie := testLogin(user)
+	request := func(method, url string, params ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, bodyParams(params...))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	t.Run("get", func(t *testing.T) {
+		w := request(http.MethodGet, "/timesheet/?date=2026-10-14")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "week of Oct 12, 2026")
+		should.ContainSubstring(t, w.Body.String(), `name="hours"`)
+	})
+	t.Run("badDate", func(t *testing.T) {
+		w := request(http.MethodGet, "/timesheet/?date=yesterday")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+	})
+	t.Run("add", func(t *testing.T) {
+		w := request(http.MethodPost, "/timesheet/",
+			"project", "test", "date", "2026-10-14", "hours", "1.5", "period", "month")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "October 2026")
+		should.ContainSubstring(t, w.Body.String(), "01:30")
+		records, err := database.GetAllRecordsForUser("test")
+		should.BeNil(t, err)
+		should.BeEqual(t, len(records), 1)
+		should.BeEqual(t, records[0].Start.Hour(), 9)
+		should.BeEqual(t, records[0].Duration(), 90*time.Minute)
+	})
+	t.Run("notEmpty", func(t *testing.T) {
+		w := request(http.MethodPost, "/timesheet/", "project", "test", "date", "2026-10-14", "hours", "1")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "already has records")
+	})
+	t.Run("invalid", func(t *testing.T) {
+		w := request(http.MethodPost, "/timesheet/", "project", "test", "date", "2026-10-15", "hours", "0")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		w = request(http.MethodPost, "/timesheet/", "project", "inactive", "date", "2026-10-15", "hours", "1")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "project is not active")
+	})
+	t.Run("future", func(t *testing.T) {
+		tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+		w := request(http.MethodPost, "/timesheet/", "project", "test", "date", tomorrow, "hours", "1")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "cannot be in the future")
+	})
+	t.Run("overlap", func(t *testing.T) {
+		should.BeNil(t, database.SaveRecord(&models.Record{
+			ID:      uuid.New(),
+			Project: "test2",
+			User:    "test",
+			Start:   time.Date(2026, 10, 17, 0, 30, 0, 0, time.Local),
+			End:     time.Date(2026, 10, 17, 1, 30, 0, 0, time.Local),
+		}))
+		w := request(http.MethodPost, "/timesheet/", "project", "test", "date", "2026-10-16", "hours", "16")
+		should.BeEqual(t, w.Code, http.StatusBadRequest)
+		should.ContainSubstring(t, w.Body.String(), "overlaps test2 record")
+	})
+	t.Run("notMember", func(t *testing.T) {
+		project, err := database.GetProject("golf")
+		should.BeNil(t, err)
+		project.Members = []models.Member{{User: "admin", Role: models.RoleOwner}}
+		should.BeNil(t, database.SaveProject(&project))
+		w := request(http.MethodPost, "/timesheet/", "project", "golf", "date", "2026-10-15", "hours", "1")
+		should.BeEqual(t, w.Code, http.StatusUnauthorized)
+	})
+	t.Run("records", func(t *testing.T) {
+		should.BeNil(t, database.SaveRecord(&models.Record{
+			ID:      uuid.New(),
+			Project: "test",
+			User:    "test",
+			Start:   time.Date(2026, 10, 14, 20, 0, 0, 0, time.Local),
+			End:     time.Date(2026, 10, 14, 21, 0, 0, 0, time.Local),
+			Note:    "evening",
+		}))
+		w := request(http.MethodGet, "/timesheet/records/?project=test&date=2026-10-14&period=week")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "Oct 14, 2026 09:00")
+		should.ContainSubstring(t, w.Body.String(), "evening")
+	})
+	t.Run("overnightRecords", func(t *testing.T) {
+		should.BeNil(t, database.SaveRecord(&models.Record{
+			ID:      uuid.New(),
+			Project: "test",
+			User:    "test",
+			Start:   time.Date(2026, 10, 12, 22, 0, 0, 0, time.Local),
+			End:     time.Date(2026, 10, 13, 2, 0, 0, 0, time.Local),
+			Note:    "late",
+		}))
+		w := request(http.MethodGet, "/timesheet/records/?project=test&date=2026-10-13&period=week")
+		should.BeEqual(t, w.Code, http.StatusOK)
+		should.ContainSubstring(t, w.Body.String(), "Oct 13, 2026 00:00")
+		should.ContainSubstring(t, w.Body.String(), "late")
+	})
+}
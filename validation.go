@@ -0,0 +1,80 @@
+package main
+
+import (
+	"time"
+
+	"github.com/devilcove/timetraced/database"
+	"github.com/devilcove/timetraced/models"
+)
+
+// maxRecordSpan is the longest duration a single time record may cover.
+const maxRecordSpan = 24 * time.Hour
+
+// canEditRecord reports whether editor is permitted to modify record.
+func canEditRecord(editor models.User, record models.Record) bool {
+	return record.User == editor.Username || editor.IsAdmin
+}
+
+// validateRecord checks the start/end of an edited record for ordering, future times,
+// maximum span and overlap with other records of the same user.  Problems are
+// recorded as field errors on edit.  If edit.Trim is set, overlapping neighbours are
+// trimmed to make room for the record and returned so they can be saved by the caller.
+func validateRecord(record models.Record, edit *models.EditRecord) ([]models.Record, error) {
+	now := time.Now()
+	if record.Start.After(now) {
+		edit.AddError("Start", "start cannot be in the future")
+	}
+	if record.End.After(now) {
+		edit.AddError("End", "end cannot be in the future")
+	}
+	if !record.End.After(record.Start) {
+		edit.AddError("End", "end must be after start")
+	} else if record.Duration() > maxRecordSpan {
+		edit.AddError("End", "record cannot span more than "+maxRecordSpan.String())
+	}
+	if edit.HasErrors() {
+		return nil, nil
+	}
+	records, err := database.GetAllRecordsForUser(record.User)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := []models.Record{}
+	for _, other := range records {
+		if other.ID == record.ID {
+			continue
+		}
+		otherEnd := other.End
+		if otherEnd.IsZero() {
+			otherEnd = now
+		}
+		if !other.Start.Before(record.End) || !record.Start.Before(otherEnd) {
+			continue
+		}
+		field := "End"
+		if other.Start.Before(record.Start) {
+			field = "Start"
+		}
+		overlap := "overlaps " + other.Project + " record " +
+			other.Start.Format("Jan 02 15:04") + " - " + otherEnd.Format("Jan 02 15:04")
+		if !edit.Trim {
+			edit.AddError(field, overlap)
+			continue
+		}
+		switch {
+		case other.Invoiced():
+			edit.AddError(field, overlap+"; cannot trim an invoiced record")
+		case other.Start.Before(record.Start) && otherEnd.After(record.End):
+			edit.AddError(field, overlap+"; cannot trim a record that encloses this one")
+		case other.Start.Before(record.Start):
+			other.End = record.Start
+			trimmed = append(trimmed, other)
+		case otherEnd.After(record.End):
+			other.Start = record.End
+			trimmed = append(trimmed, other)
+		default:
+			edit.AddError(field, overlap+"; cannot trim a record enclosed by this one")
+		}
+	}
+	return trimmed, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+	"github.com/devilcove/timetraced/models"
+)
+
+func TestCanEditRecord(t *testing.T) {
+	record := models.Record{User: "test"}
+	should.BeTrue(t, canEditRecord(models.User{Username: "test"}, record))
+	should.BeTrue(t, canEditRecord(models.User{Username: "admin", IsAdmin: true}, record))
+	should.BeFalse(t, canEditRecord(models.User{Username: "other"}, record))
+}
+
+func TestValidateRecord(t *testing.T) {
+	deleteAllRecords()
+	now := time.Now()
+	t.Run("valid", func(t *testing.T) {
+		edit := models.EditRecord{}
+		trimmed, err := validateRecord(models.Record{
+			User:  "test",
+			Start: now.Add(-time.Hour),
+			End:   now.Add(-time.Minute),
+		}, &edit)
+		should.BeNil(t, err)
+		should.BeEmpty(t, trimmed)
+		should.BeFalse(t, edit.HasErrors())
+	})
+	t.Run("startInFuture", func(t *testing.T) {
+		edit := models.EditRecord{}
+		_, err := validateRecord(models.Record{
+			User:  "test",
+			Start: now.Add(time.Hour),
+			End:   now.Add(time.Hour * 2),
+		}, &edit)
+		should.BeNil(t, err)
+		should.BeEqual(t, edit.Errors["Start"], "start cannot be in the future")
+	})
+	t.Run("equal", func(t *testing.T) {
+		edit := models.EditRecord{}
+		_, err := validateRecord(models.Record{
+			User:  "test",
+			Start: now.Add(-time.Hour),
+			End:   now.Add(-time.Hour),
+		}, &edit)
+		should.BeNil(t, err)
+		should.BeEqual(t, edit.Errors["End"], "end must be after start")
+	})
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cell styles, which are indexes into the cellXfs of xlsxStyles.
+const (
+	styleDefault = iota
+	styleDateTime
+	styleDuration
+	styleHeader
+	styleHours
+)
+
+// maxSheetName is the maximum length of a worksheet name.
+const maxSheetName = 31
+
+// xlsxEpoch is the zero date of spreadsheet serial dates.
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxCell is a cell of a worksheet: text, a number or, if Formula is set, a formula
+// whose last calculated value is the number.  The zero value is an empty cell.
+type xlsxCell struct {
+	Text    string
+	Number  float64
+	Formula string
+	Style   int
+	IsText  bool
+}
+
+// xlsxSheet is a worksheet.
+type xlsxSheet struct {
+	Name string
+	Rows [][]xlsxCell
+}
+
+// textCell returns a text cell.
+func textCell(text string, style int) xlsxCell {
+	return xlsxCell{Text: text, Style: style, IsText: true}
+}
+
+// timeCell returns a date and time cell with the wall clock time of t.
+func timeCell(t time.Time) xlsxCell {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	return xlsxCell{Number: wall.Sub(xlsxEpoch).Hours() / 24, Style: styleDateTime}
+}
+
+// durationCell returns a duration cell, which is stored as a fraction of a day.
+func durationCell(d time.Duration) xlsxCell {
+	return xlsxCell{Number: d.Hours() / 24, Style: styleDuration}
+}
+
+// formulaCell returns a formula cell with its calculated value.
+func formulaCell(formula string, value float64, style int) xlsxCell {
+	return xlsxCell{Formula: formula, Number: value, Style: style}
+}
+
+// cellName returns the A1 style reference of a cell from zero based indexes.
+func cellName(column, row int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+	return name + strconv.Itoa(row+1)
+}
+
+// sheetNames returns valid and unique worksheet names for the given names.
+func sheetNames(names []string) []string {
+	replacer := strings.NewReplacer("[", "(", "]", ")", ":", "-", "*", "-", "?", "-", "/", "-", "\\", "-")
+	valid := []string{}
+	used := map[string]bool{}
+	for _, name := range names {
+		name = strings.Trim(replacer.Replace(name), "'")
+		if name == "" {
+			name = "Sheet"
+		}
+		name = truncate(name, maxSheetName)
+		unique := name
+		for i := 2; used[strings.ToLower(unique)]; i++ {
+			suffix := fmt.Sprintf(" (%d)", i)
+			unique = truncate(name, maxSheetName-len(suffix)) + suffix
+		}
+		used[strings.ToLower(unique)] = true
+		valid = append(valid, unique)
+	}
+	return valid
+}
+
+// truncate shortens a string to at most n runes.
+func truncate(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
+
+// writeXLSX writes the worksheets as an XLSX workbook.
+func writeXLSX(w io.Writer, sheets []xlsxSheet) error {
+	archive := zip.NewWriter(w)
+	names := []string{}
+	for _, sheet := range sheets {
+		names = append(names, sheet.Name)
+	}
+	names = sheetNames(names)
+	var contentTypes, workbook, relationships strings.Builder
+	for i, name := range names {
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" `+
+			`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(name), i+1, i+1)
+		fmt.Fprintf(&relationships, `<Relationship Id="rId%d" `+
+			`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" `+
+			`Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&relationships, `<Relationship Id="rId%d" `+
+		`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" `+
+		`Target="styles.xml"/>`, len(names)+1)
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", fmt.Sprintf(xlsxContentTypes, contentTypes.String())},
+		{"_rels/.rels", xlsxRelationships},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, workbook.String())},
+		{"xl/_rels/workbook.xml.rels", fmt.Sprintf(xlsxWorkbookRelationships, relationships.String())},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for i, sheet := range sheets {
+		parts = append(parts, struct{ name, content string }{
+			fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), worksheet(sheet),
+		})
+	}
+	for _, part := range parts {
+		f, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// worksheet returns the SpreadsheetML of a worksheet.
+func worksheet(sheet xlsxSheet) string {
+	var data strings.Builder
+	for r, row := range sheet.Rows {
+		fmt.Fprintf(&data, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			if cell == (xlsxCell{}) {
+				continue
+			}
+			ref := cellName(c, r)
+			switch {
+			case cell.IsText:
+				fmt.Fprintf(&data, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+					ref, cell.Style, xmlEscape(cell.Text))
+			case cell.Formula != "":
+				fmt.Fprintf(&data, `<c r="%s" s="%d"><f>%s</f><v>%s</v></c>`,
+					ref, cell.Style, xmlEscape(cell.Formula), formatNumber(cell.Number))
+			default:
+				fmt.Fprintf(&data, `<c r="%s" s="%d"><v>%s</v></c>`, ref, cell.Style, formatNumber(cell.Number))
+			}
+		}
+		data.WriteString("</row>")
+	}
+	return fmt.Sprintf(xlsxWorksheet, data.String())
+}
+
+// formatNumber formats a cell value.
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// xmlEscape escapes text for inclusion in XML.
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ` +
+	`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ` +
+	`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`%s</Types>`
+
+const xlsxRelationships = xml.Header +
+	`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" ` +
+	`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" ` +
+	`Target="xl/workbook.xml"/></Relationships>`
+
+const xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets>%s</sheets><calcPr fullCalcOnLoad="1"/></workbook>`
+
+const xlsxWorkbookRelationships = xml.Header +
+	`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">%s</Relationships>`
+
+// xlsxStyles defines the cell styles: default, date and time, duration in hours and
+// minutes, bold header and decimal hours.
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm"/>` +
+	`<numFmt numFmtId="165" formatCode="[h]:mm"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font>` +
+	`<font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill>` +
+	`<fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="5">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="2" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs></styleSheet>`
+
+const xlsxWorksheet = xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<sheetData>%s</sheetData></worksheet>`
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Kairum-Labs/should"
+)
+
+func TestCellName(t *testing.T) {
+	should.BeEqual(t, cellName(0, 0), "A1")
+	should.BeEqual(t, cellName(25, 9), "Z10")
+	should.BeEqual(t, cellName(26, 0), "AA1")
+	should.BeEqual(t, cellName(701, 0), "ZZ1")
+	should.BeEqual(t, cellName(702, 0), "AAA1")
+}
+
+func TestSheetNames(t *testing.T) {
+	names := sheetNames([]string{
+		"Summary", "summary", "a/b:c", "", "'quoted'", "a very long project name that exceeds the limit",
+	})
+	should.BeEqual(t, names, []string{
+		"Summary", "summary (2)", "a-b-c", "Sheet", "quoted", "a very long project name that e",
+	})
+}
+
+func TestTimeCell(t *testing.T) {
+	cell := timeCell(time.Date(2026, 1, 1, 18, 0, 0, 0, time.FixedZone("test", -5*3600)))
+	should.BeEqual(t, cell.Number, 46023.75)
+	should.BeEqual(t, durationCell(90*time.Minute).Number, 0.0625)
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeXLSX(&buf, []xlsxSheet{
+		{Name: "first", Rows: [][]xlsxCell{
+			{textCell("a < b & c", styleHeader), {}, durationCell(time.Hour)},
+			{formulaCell("SUM(C1:C1)", 1.0/24, styleDuration)},
+		}},
+		{Name: "second"},
+	})
+	should.BeNil(t, err)
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	should.BeNil(t, err)
+	parts := map[string]string{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		should.BeNil(t, err)
+		data, err := io.ReadAll(r)
+		should.BeNil(t, err)
+		parts[f.Name] = string(data)
+	}
+	should.BeEqual(t, len(parts), 7)
+	should.ContainSubstring(t, parts["[Content_Types].xml"], "/xl/worksheets/sheet2.xml")
+	should.ContainSubstring(t, parts["xl/workbook.xml"], `<sheet name="second" sheetId="2" r:id="rId2"/>`)
+	should.ContainSubstring(t, parts["xl/_rels/workbook.xml.rels"], `Id="rId3"`)
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	should.ContainSubstring(t, sheet, `<c r="A1" s="3" t="inlineStr"><is><t xml:space="preserve">a &lt; b &amp; c</t>`)
+	should.BeFalse(t, bytes.Contains([]byte(sheet), []byte(`r="B1"`)))
+	should.ContainSubstring(t, sheet, `<c r="C1" s="2"><v>0.041666666666666664</v></c>`)
+	should.ContainSubstring(t, sheet, `<c r="A2" s="2"><f>SUM(C1:C1)</f>`)
+}